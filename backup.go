@@ -0,0 +1,202 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	backupSuffix     = ".claude-bell-backup"
+	backupTimeLayout = "20060102-150405"
+	defaultBackups   = 10
+)
+
+// settingsBackup is one saved copy of a settings file.
+type settingsBackup struct {
+	ID   string
+	Path string
+	Time time.Time
+	Size int64
+}
+
+// backupSettings copies the settings file to a timestamped backup next to it
+// and prunes the oldest backups beyond keep.
+func backupSettings(path string, keep int) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	id := time.Now().Format(backupTimeLayout)
+	backupPath := path + backupSuffix + "." + id
+	for n := 2; ; n++ {
+		if _, err := os.Stat(backupPath); os.IsNotExist(err) {
+			break
+		}
+		backupPath = fmt.Sprintf("%s%s.%s-%d", path, backupSuffix, id, n)
+	}
+	if err := os.WriteFile(backupPath, data, 0644); err != nil {
+		return err
+	}
+
+	return pruneBackups(path, keep)
+}
+
+// listBackups returns the backups of a settings file, newest first. A backup
+// from older versions without a timestamp is listed with the ID "legacy".
+func listBackups(path string) ([]settingsBackup, error) {
+	matches, err := filepath.Glob(path + backupSuffix + "*")
+	if err != nil {
+		return nil, err
+	}
+
+	var backups []settingsBackup
+	for _, m := range matches {
+		info, err := os.Stat(m)
+		if err != nil || info.IsDir() {
+			continue
+		}
+		rest := strings.TrimPrefix(m, path+backupSuffix)
+		b := settingsBackup{Path: m, Time: info.ModTime(), Size: info.Size()}
+		switch {
+		case rest == "":
+			b.ID = "legacy"
+		case strings.HasPrefix(rest, "."):
+			b.ID = rest[1:]
+			stamp := b.ID[:min(len(b.ID), len(backupTimeLayout))]
+			if t, err := time.ParseInLocation(backupTimeLayout, stamp, time.Local); err == nil {
+				b.Time = t
+			}
+		default:
+			continue
+		}
+		backups = append(backups, b)
+	}
+
+	sort.Slice(backups, func(i, j int) bool {
+		if backups[i].Time.Equal(backups[j].Time) {
+			return backups[i].ID > backups[j].ID
+		}
+		return backups[i].Time.After(backups[j].Time)
+	})
+	return backups, nil
+}
+
+func pruneBackups(path string, keep int) error {
+	if keep <= 0 {
+		keep = defaultBackups
+	}
+	backups, err := listBackups(path)
+	if err != nil {
+		return err
+	}
+	for _, b := range backups[min(keep, len(backups)):] {
+		if err := os.Remove(b.Path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func cmdBackups() {
+	fs := flag.NewFlagSet("backups", flag.ExitOnError)
+	scope := fs.String("scope", "user", "settings file: user, project, or local")
+	fs.Parse(os.Args[2:])
+
+	settingsPath, err := claudeSettingsPathForScope(*scope)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+
+	backups, err := listBackups(settingsPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if len(backups) == 0 {
+		fmt.Printf("No backups of %s.\n", displayPath(settingsPath))
+		return
+	}
+
+	fmt.Printf("Backups of %s (newest first):\n", displayPath(settingsPath))
+	for _, b := range backups {
+		fmt.Printf("  %-20s %s  %d bytes\n", b.ID, b.Time.Format("2006-01-02 15:04:05"), b.Size)
+	}
+	fmt.Println()
+	fmt.Println("Restore one with: claude-bell restore <id>")
+}
+
+func cmdRestore() {
+	fs := flag.NewFlagSet("restore", flag.ExitOnError)
+	scope := fs.String("scope", "user", "settings file: user, project, or local")
+	fs.Parse(os.Args[2:])
+
+	if fs.NArg() > 1 {
+		fmt.Fprintln(os.Stderr, "usage: claude-bell restore [--scope s] [id]")
+		os.Exit(1)
+	}
+
+	settingsPath, err := claudeSettingsPathForScope(*scope)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+
+	backups, err := listBackups(settingsPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+	if len(backups) == 0 {
+		fmt.Fprintf(os.Stderr, "error: no backups of %s\n", displayPath(settingsPath))
+		os.Exit(1)
+	}
+
+	chosen := backups[0]
+	if fs.NArg() == 1 {
+		id := fs.Arg(0)
+		found := false
+		for _, b := range backups {
+			if b.ID == id {
+				chosen, found = b, true
+				break
+			}
+		}
+		if !found {
+			fmt.Fprintf(os.Stderr, "error: backup %q not found (see 'claude-bell backups')\n", id)
+			os.Exit(1)
+		}
+	}
+
+	data, err := os.ReadFile(chosen.Path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+	if _, err := parseSettingsDoc(data); err != nil {
+		fmt.Fprintf(os.Stderr, "error: backup %s is not valid settings JSON: %v\n", chosen.ID, err)
+		os.Exit(1)
+	}
+
+	cfg, _ := loadConfig()
+	if err := backupSettings(settingsPath, cfg.BackupKeep); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: could not back up current settings: %v\n", err)
+	}
+
+	if err := writeFileAtomic(settingsPath, data); err != nil {
+		fmt.Fprintf(os.Stderr, "error writing settings: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("Restored %s from backup %s\n", displayPath(settingsPath), chosen.ID)
+}
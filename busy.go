@@ -0,0 +1,136 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// Actions for BusyConfig when the microphone or other audio is in use.
+const (
+	busyIgnore = ""
+	busySkip   = "skip"
+	busyDuck   = "duck"
+	busyVisual = "visual"
+)
+
+// BusyConfig adjusts sounds while a call or other audio is active, as
+// reported by PulseAudio or PipeWire (via pactl).
+type BusyConfig struct {
+	Mic        string  `json:"mic,omitempty"`     // action while something records from a microphone
+	Playing    string  `json:"playing,omitempty"` // action while other audio is playing
+	DuckVolume float64 `json:"duck_volume,omitempty"`
+}
+
+const defaultDuckVolume = 0.2
+
+// busyAction returns what to do about the current audio activity, checking
+// microphone use first since it usually means a call.
+func busyAction(b *BusyConfig) (string, error) {
+	if b == nil || (b.Mic == busyIgnore && b.Playing == busyIgnore) {
+		return busyIgnore, nil
+	}
+	for _, action := range []string{b.Mic, b.Playing} {
+		switch action {
+		case busyIgnore, busySkip, busyDuck, busyVisual:
+		default:
+			return busyIgnore, fmt.Errorf("unknown busy action %q (use skip, duck, or visual)", action)
+		}
+	}
+
+	if b.Mic != busyIgnore && activeStreams("source-outputs") > 0 {
+		return b.Mic, nil
+	}
+	if b.Playing != busyIgnore && activeStreams("sink-inputs") > 0 {
+		return b.Playing, nil
+	}
+	return busyIgnore, nil
+}
+
+// activeStreams counts uncorked streams of a pactl object kind:
+// "source-outputs" are recording streams, "sink-inputs" playing ones. Streams
+// from claude-bell's own players, such as another event's chime, do not
+// count. It returns 0 when pactl is not available.
+func activeStreams(kind string) int {
+	out, err := exec.Command("pactl", "list", kind).Output()
+	if err != nil {
+		return 0
+	}
+
+	count := 0
+	inStream := false
+	corked, ours := false, false
+	flush := func() {
+		if inStream && !corked && !ours {
+			count++
+		}
+	}
+	sc := bufio.NewScanner(bytes.NewReader(out))
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if strings.HasPrefix(line, "Source Output #") || strings.HasPrefix(line, "Sink Input #") {
+			flush()
+			inStream, corked, ours = true, false, false
+			continue
+		}
+		if v, ok := strings.CutPrefix(line, "Corked:"); ok {
+			corked = strings.TrimSpace(v) == "yes"
+		}
+		for _, prop := range []string{"application.process.binary", "application.name"} {
+			if v, ok := strings.CutPrefix(line, prop+" = "); ok && isOwnPlayer(strings.Trim(v, `"`)) {
+				ours = true
+			}
+		}
+	}
+	flush()
+	return count
+}
+
+// isOwnPlayer reports whether name is a program claude-bell plays sounds or
+// speech with.
+func isOwnPlayer(name string) bool {
+	name = filepath.Base(name)
+	if name == "claude-bell" {
+		return true
+	}
+	for _, b := range audioBackends {
+		if b.name == name {
+			return true
+		}
+	}
+	for _, e := range speechEngines {
+		if e.name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// applyBusyPolicy decides how an event sounds given current audio activity.
+// It returns the volume to use, whether the sound should play, and whether a
+// desktop notification should be shown in its place.
+func applyBusyPolicy(cfg Config, volume float64) (vol float64, play, visual bool) {
+	action, err := busyAction(cfg.Busy)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "claude-bell: warning: %v\n", err)
+		return volume, true, false
+	}
+
+	switch action {
+	case busySkip:
+		return 0, false, false
+	case busyDuck:
+		duck := defaultDuckVolume
+		if cfg.Busy.DuckVolume > 0 {
+			duck = clampVolume(cfg.Busy.DuckVolume)
+		}
+		return min(volume, duck), true, false
+	case busyVisual:
+		return 0, false, true
+	}
+	return volume, true, false
+}
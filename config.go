@@ -2,18 +2,78 @@ package main
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 )
 
 type Config struct {
-	Stop         string  `json:"stop,omitempty"`
-	Notification string  `json:"notification,omitempty"`
-	Limit        string  `json:"limit,omitempty"`
-	Volume       float64 `json:"volume"`
+	Version int `json:"version"` // schema version, see configVersion
+
+	// Events maps each event to the name of its sound. Events without a
+	// sound are left out.
+	Events map[string]string `json:"events,omitempty"`
+	Volume float64           `json:"volume"`
+	// Volumes overrides Volume for individual events.
+	Volumes    map[string]float64 `json:"volumes,omitempty"`
+	BackupKeep int                `json:"backup_keep,omitempty"` // settings backups to retain
+
+	// Cooldowns holds the minimum seconds between two sounds for an event,
+	// and RateLimit the minimum seconds between any two sounds.
+	Cooldowns map[string]float64 `json:"cooldowns,omitempty"`
+	RateLimit float64            `json:"rate_limit,omitempty"`
+
+	// PlaybackPolicy decides what happens when a sound is already playing:
+	// "queue" (default) waits for it, "drop" skips the new sound, and
+	// "preempt" stops it if the new event has a higher priority.
+	PlaybackPolicy string         `json:"playback_policy,omitempty"`
+	Priorities     map[string]int `json:"priorities,omitempty"`
+
+	Quiet *QuietConfig `json:"quiet,omitempty"`
+	Busy  *BusyConfig  `json:"busy,omitempty"`
+
+	// Notify enables desktop notifications for the events it lists.
+	Notify map[string]*NotifyConfig `json:"notify,omitempty"`
+
+	// Webhook posts events to an HTTP endpoint such as ntfy or Gotify.
+	Webhook *WebhookConfig `json:"webhook,omitempty"`
+
+	// Speak announces the events it lists with text-to-speech.
+	Speak map[string]*SpeakConfig `json:"speak,omitempty"`
+
+	// Terminal alerts the terminal Claude Code runs in, e.g. over SSH.
+	Terminal map[string]*TerminalConfig `json:"terminal,omitempty"`
+
+	// Remote sends events to another machine's 'claude-bell serve' instead
+	// of playing them here; Serve configures that server.
+	Remote *RemoteConfig `json:"remote,omitempty"`
+	Serve  *ServeConfig  `json:"serve,omitempty"`
+
+	// Profiles are named alternatives to the sounds, volumes and channels
+	// above. Profile is the one chosen with 'claude-bell profile use'; when
+	// it is empty, the first matching ProfileRules entry picks one.
+	Profiles     map[string]*Profile `json:"profiles,omitempty"`
+	Profile      string              `json:"profile,omitempty"`
+	ProfileRules []ProfileRule       `json:"profile_rules,omitempty"`
 }
 
+// configDir is where claude-bell keeps its config and state:
+// $CLAUDE_BELL_CONFIG if set, else $XDG_CONFIG_HOME/claude-bell, else
+// ~/.config/claude-bell.
 func configDir() string {
+	if dir := os.Getenv("CLAUDE_BELL_CONFIG"); dir != "" {
+		return dir
+	}
+	if dir := xdgDir("XDG_CONFIG_HOME", ".config"); dir != "" {
+		return filepath.Join(dir, "claude-bell")
+	}
+	return ""
+}
+
+// legacyConfigDir is where versions before XDG support kept everything.
+func legacyConfigDir() string {
 	home, err := os.UserHomeDir()
 	if err != nil {
 		return ""
@@ -21,24 +81,137 @@ func configDir() string {
 	return filepath.Join(home, ".config", "claude-bell")
 }
 
+// cacheDir holds files claude-bell can regenerate: $XDG_CACHE_HOME/claude-bell,
+// or ~/.cache/claude-bell.
+func cacheDir() string {
+	if dir := xdgDir("XDG_CACHE_HOME", ".cache"); dir != "" {
+		return filepath.Join(dir, "claude-bell")
+	}
+	return ""
+}
+
+// xdgDir returns the base directory named by an XDG environment variable,
+// or fallback under the home directory when it is unset or not absolute, as
+// the spec requires.
+func xdgDir(env, fallback string) string {
+	if dir := os.Getenv(env); filepath.IsAbs(dir) {
+		return dir
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, fallback)
+}
+
 func configPath() string {
 	return filepath.Join(configDir(), "config.json")
 }
 
+func statePath() string {
+	return filepath.Join(configDir(), "state.json")
+}
+
 func soundsDir() string {
-	return filepath.Join(configDir(), "sounds")
+	return filepath.Join(cacheDir(), "sounds")
 }
 
-func claudeSettingsPath() string {
+// claudeConfigDir is Claude Code's own config directory: $CLAUDE_CONFIG_DIR
+// if set, else ~/.claude.
+func claudeConfigDir() string {
+	if dir := os.Getenv("CLAUDE_CONFIG_DIR"); dir != "" {
+		return dir
+	}
 	home, err := os.UserHomeDir()
 	if err != nil {
 		return ""
 	}
-	return filepath.Join(home, ".claude", "settings.json")
+	return filepath.Join(home, ".claude")
+}
+
+func claudeSettingsPath() string {
+	return filepath.Join(claudeConfigDir(), "settings.json")
 }
 
+// settingsScopes lists the Claude Code settings files hooks can be written to.
+var settingsScopes = []string{"user", "project", "local"}
+
+// claudeSettingsPathForScope returns the settings file for a scope: the
+// user-wide file, the shared project file, or the personal project file.
+func claudeSettingsPathForScope(scope string) (string, error) {
+	switch scope {
+	case "", "user":
+		return claudeSettingsPath(), nil
+	case "project":
+		root, err := projectRoot()
+		if err != nil {
+			return "", err
+		}
+		path := filepath.Join(root, ".claude", "settings.json")
+		if samePath(path, claudeSettingsPath()) {
+			return "", fmt.Errorf("project settings in %s would be the user settings file; use --scope user", displayPath(root))
+		}
+		return path, nil
+	case "local":
+		root, err := projectRoot()
+		if err != nil {
+			return "", err
+		}
+		return filepath.Join(root, ".claude", "settings.local.json"), nil
+	}
+	return "", fmt.Errorf("unknown scope %q (use %s)", scope, strings.Join(settingsScopes, ", "))
+}
+
+// projectRoot returns the nearest ancestor of the working directory that
+// contains a .git entry.
+func projectRoot() (string, error) {
+	wd, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+	for dir := wd; ; {
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			return dir, nil
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", fmt.Errorf("%s is not inside a git repository; run from the project or use --scope user", displayPath(wd))
+		}
+		dir = parent
+	}
+}
+
+// loadPlayConfig is loadConfig for playing events, typically in a hook
+// where nobody sees errors. Problems found by validation are printed as a
+// warning and the settings that could be read are used anyway, so a typo
+// does not silence the bell; 'config validate' and doctor stay strict.
+func loadPlayConfig() (Config, error) {
+	cfg, err := loadConfig()
+	var cfgErr *configError
+	if errors.As(err, &cfgErr) {
+		fmt.Fprintf(os.Stderr, "claude-bell: warning: %v\n", err)
+		return cfg, nil
+	}
+	return cfg, err
+}
+
+// displayPath shortens a path under the home directory to ~/... for output.
+func displayPath(path string) string {
+	home, err := os.UserHomeDir()
+	if err != nil || home == "" {
+		return path
+	}
+	if rel, err := filepath.Rel(home, path); err == nil && !strings.HasPrefix(rel, "..") {
+		return filepath.Join("~", rel)
+	}
+	return path
+}
+
+// loadConfig reads the config, migrating it to the current schema version
+// first if it is older. Unknown keys and invalid values are reported as a
+// *configError rather than ignored.
 func loadConfig() (Config, error) {
-	cfg := Config{Volume: 1.0}
+	cfg := Config{Version: configVersion, Volume: 1.0}
 	data, err := os.ReadFile(configPath())
 	if err != nil {
 		if os.IsNotExist(err) {
@@ -47,47 +220,51 @@ func loadConfig() (Config, error) {
 		return cfg, err
 	}
 
-	var disk struct {
-		Stop         string   `json:"stop,omitempty"`
-		Notification string   `json:"notification,omitempty"`
-		Limit        string   `json:"limit,omitempty"`
-		Volume       *float64 `json:"volume"`
+	data, err = migrateConfigFile(configPath(), data)
+	if err != nil {
+		return cfg, err
 	}
-	if err := json.Unmarshal(data, &disk); err != nil {
+	if err := decodeConfig(data, &cfg); err != nil {
 		return cfg, err
 	}
+	return cfg, nil
+}
 
-	cfg.Stop = disk.Stop
-	cfg.Notification = disk.Notification
-	cfg.Limit = disk.Limit
-	if disk.Volume != nil {
-		cfg.Volume = clampVolume(*disk.Volume)
+// eventVolume returns the volume for an event: its override if set,
+// otherwise the global volume.
+func eventVolume(cfg Config, event string) float64 {
+	if v, ok := cfg.Volumes[event]; ok {
+		return clampVolume(v)
 	}
+	return clampVolume(cfg.Volume)
+}
 
-	return cfg, nil
+// eventPriority returns the configured priority of an event, falling back to
+// EventPriorities.
+func eventPriority(cfg Config, event string) int {
+	if p, ok := cfg.Priorities[event]; ok {
+		return p
+	}
+	return EventPriorities[event]
 }
 
 func getConfigField(cfg Config, event string) string {
-	switch event {
-	case "stop":
-		return cfg.Stop
-	case "notification":
-		return cfg.Notification
-	case "limit":
-		return cfg.Limit
-	}
-	return ""
+	return cfg.Events[event]
 }
 
+// setConfigField returns cfg with the sound for event set to value, or
+// removed when value is empty. cfg itself is not modified.
 func setConfigField(cfg Config, event, value string) Config {
-	switch event {
-	case "stop":
-		cfg.Stop = value
-	case "notification":
-		cfg.Notification = value
-	case "limit":
-		cfg.Limit = value
+	events := make(map[string]string, len(cfg.Events)+1)
+	for e, v := range cfg.Events {
+		events[e] = v
+	}
+	if value == "" {
+		delete(events, event)
+	} else {
+		events[event] = value
 	}
+	cfg.Events = events
 	return cfg
 }
 
@@ -96,6 +273,7 @@ func saveConfig(cfg Config) error {
 	if err := os.MkdirAll(dir, 0755); err != nil {
 		return err
 	}
+	cfg.Version = configVersion
 	cfg.Volume = clampVolume(cfg.Volume)
 	data, err := json.MarshalIndent(cfg, "", "  ")
 	if err != nil {
@@ -0,0 +1,463 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+func cmdConfig() {
+	args := os.Args[2:]
+	if len(args) == 0 {
+		configUsage()
+	}
+
+	switch args[0] {
+	case "get":
+		if len(args) > 2 {
+			configUsage()
+		}
+		path := ""
+		if len(args) == 2 {
+			path = args[1]
+		}
+		configGet(path)
+	case "set":
+		if len(args) != 3 {
+			configUsage()
+		}
+		configSet(args[1], args[2], false)
+	case "unset":
+		if len(args) != 2 {
+			configUsage()
+		}
+		configSet(args[1], "", true)
+	case "edit":
+		configEdit()
+	case "validate":
+		configValidate()
+	case "path":
+		configPaths()
+	default:
+		configUsage()
+	}
+}
+
+func configUsage() {
+	fmt.Fprintln(os.Stderr, "usage: claude-bell config get [path]")
+	fmt.Fprintln(os.Stderr, "       claude-bell config set <path> <value>")
+	fmt.Fprintln(os.Stderr, "       claude-bell config unset <path>")
+	fmt.Fprintln(os.Stderr, "       claude-bell config edit")
+	fmt.Fprintln(os.Stderr, "       claude-bell config validate")
+	fmt.Fprintln(os.Stderr, "       claude-bell config path")
+	os.Exit(1)
+}
+
+// configGet prints the effective value at a dotted path, including defaults
+// for keys the file leaves out. Strings print bare, everything else as JSON.
+func configGet(path string) {
+	cfg, err := loadConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error loading config: %v\n", err)
+		os.Exit(1)
+	}
+	data, err := json.Marshal(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+	root, err := decodeOrderedJSON(data)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+
+	v, ok := lookupPath(root, splitConfigPath(path))
+	if !ok {
+		fmt.Fprintf(os.Stderr, "error: %s is not set\n", path)
+		os.Exit(1)
+	}
+	if s, isString := v.(string); isString {
+		fmt.Println(s)
+		return
+	}
+	out, err := encodeOrderedJSON(v, "", "  ")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Println(string(out))
+}
+
+// configSet sets or removes the value at a dotted path in the config file,
+// keeping the rest of the file's order, and refuses to save an invalid
+// result. value is parsed as JSON if possible and used as a string otherwise,
+// so both 'volume 0.5' and 'events.stop Resolve' work.
+func configSet(path, value string, unset bool) {
+	keys := splitConfigPath(path)
+	if len(keys) == 0 {
+		configUsage()
+	}
+
+	obj, err := readConfigObject()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error loading config: %v\n", err)
+		os.Exit(1)
+	}
+	original, err := encodeOrderedJSON(obj, "", "  ")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+	before, err := configProblems(original)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if unset {
+		if !deletePath(obj, keys) {
+			fmt.Fprintf(os.Stderr, "error: %s is not set\n", path)
+			os.Exit(1)
+		}
+	} else {
+		if err := setPath(obj, keys, parseConfigValue(value)); err != nil {
+			fmt.Fprintf(os.Stderr, "error: %s: %v\n", path, err)
+			os.Exit(1)
+		}
+	}
+
+	data, err := encodeOrderedJSON(obj, "", "  ")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+	// Only refuse problems this change adds, so a broken config can be
+	// repaired one key at a time.
+	after, err := configProblems(data)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+	var added, remaining []string
+	for _, p := range after {
+		if slices.Contains(before, p) {
+			remaining = append(remaining, p)
+		} else {
+			added = append(added, p)
+		}
+	}
+	if len(added) > 0 {
+		fmt.Fprintf(os.Stderr, "error: not saved, the result would be invalid:\n%v\n", &configError{path: configPath(), problems: added})
+		os.Exit(1)
+	}
+	if err := writeFileAtomic(configPath(), data); err != nil {
+		fmt.Fprintf(os.Stderr, "error saving config: %v\n", err)
+		os.Exit(1)
+	}
+
+	if unset {
+		fmt.Printf("Removed %s\n", path)
+	} else {
+		v, _ := lookupPath(obj, keys)
+		out, _ := encodeOrderedJSON(v, "", "")
+		fmt.Printf("Set %s to %s\n", path, out)
+	}
+	if len(remaining) > 0 {
+		fmt.Fprintf(os.Stderr, "The config still has problems:\n%v\n", &configError{path: configPath(), problems: remaining})
+	}
+}
+
+// readConfigObject returns the config file, upgraded to the current version,
+// as an ordered object. A missing file yields a new one.
+func readConfigObject() (*jsonObject, error) {
+	data, err := os.ReadFile(configPath())
+	if os.IsNotExist(err) {
+		return newJSONObject().Set("version", json.Number(strconv.Itoa(configVersion))), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if data, err = migrateConfigFile(configPath(), data); err != nil {
+		return nil, err
+	}
+	decoded, err := decodeOrderedJSON(data)
+	if err != nil {
+		return nil, err
+	}
+	obj, ok := decoded.(*jsonObject)
+	if !ok {
+		return nil, errors.New("expected a JSON object")
+	}
+	return obj, nil
+}
+
+func splitConfigPath(path string) []string {
+	if path == "" {
+		return nil
+	}
+	return strings.Split(path, ".")
+}
+
+// parseConfigValue interprets a command-line value as JSON, falling back to
+// a plain string.
+func parseConfigValue(s string) any {
+	if v, err := decodeOrderedJSON([]byte(s)); err == nil {
+		return v
+	}
+	return s
+}
+
+// lookupPath follows keys through objects and, for numeric keys, lists.
+func lookupPath(v any, keys []string) (any, bool) {
+	for _, key := range keys {
+		switch node := v.(type) {
+		case *jsonObject:
+			child, ok := node.Get(key)
+			if !ok {
+				return nil, false
+			}
+			v = child
+		case []any:
+			i, err := strconv.Atoi(key)
+			if err != nil || i < 0 || i >= len(node) {
+				return nil, false
+			}
+			v = node[i]
+		default:
+			return nil, false
+		}
+	}
+	return v, true
+}
+
+// setPath stores value at keys, creating objects along the way.
+func setPath(obj *jsonObject, keys []string, value any) error {
+	for i, key := range keys[:len(keys)-1] {
+		child, ok := obj.Get(key)
+		if !ok {
+			next := newJSONObject()
+			obj.Set(key, next)
+			obj = next
+			continue
+		}
+		switch node := child.(type) {
+		case *jsonObject:
+			obj = node
+		case []any:
+			idx, err := strconv.Atoi(keys[i+1])
+			if err != nil || idx < 0 || idx >= len(node) {
+				return fmt.Errorf("%s is a list; use an index from 0 to %d", strings.Join(keys[:i+1], "."), len(node)-1)
+			}
+			if i+1 == len(keys)-1 {
+				node[idx] = value
+				return nil
+			}
+			next, isObj := node[idx].(*jsonObject)
+			if !isObj {
+				return fmt.Errorf("%s is not an object", strings.Join(keys[:i+2], "."))
+			}
+			return setPath(next, keys[i+2:], value)
+		default:
+			return fmt.Errorf("%s is not an object", strings.Join(keys[:i+1], "."))
+		}
+	}
+	obj.Set(keys[len(keys)-1], value)
+	return nil
+}
+
+// deletePath removes the value at keys, reporting whether it existed.
+func deletePath(obj *jsonObject, keys []string) bool {
+	parent, ok := lookupPath(obj, keys[:len(keys)-1])
+	if !ok {
+		return false
+	}
+	last := keys[len(keys)-1]
+	switch node := parent.(type) {
+	case *jsonObject:
+		if _, ok := node.Get(last); !ok {
+			return false
+		}
+		node.Delete(last)
+		return true
+	case []any:
+		i, err := strconv.Atoi(last)
+		if err != nil || i < 0 || i >= len(node) {
+			return false
+		}
+		// Lists are stored by value in their parent, so rebuild it there.
+		return setPath(obj, keys[:len(keys)-1], append(node[:i:i], node[i+1:]...)) == nil
+	}
+	return false
+}
+
+// configEdit opens the config in $VISUAL or $EDITOR and saves it only once it
+// validates.
+func configEdit() {
+	obj, err := readConfigObject()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error loading config: %v\n", err)
+		os.Exit(1)
+	}
+	original, err := encodeOrderedJSON(obj, "", "  ")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+	original = append(original, '\n')
+
+	tmp, err := os.CreateTemp("", "claude-bell-config-*.json")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+	defer os.Remove(tmp.Name())
+	tmp.Write(original)
+	tmp.Close()
+
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+
+	reader := bufio.NewReader(os.Stdin)
+	for {
+		fields := strings.Fields(editor)
+		cmd := exec.Command(fields[0], append(fields[1:], tmp.Name())...)
+		cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+		if err := cmd.Run(); err != nil {
+			fmt.Fprintf(os.Stderr, "error running %s: %v\n", editor, err)
+			os.Exit(1)
+		}
+
+		edited, err := os.ReadFile(tmp.Name())
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(1)
+		}
+		if bytes.Equal(edited, original) {
+			fmt.Println("No changes.")
+			return
+		}
+
+		problems, err := configProblems(edited)
+		if err == nil && len(problems) > 0 {
+			err = &configError{path: configPath(), problems: problems}
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			// End of input means nobody is there to edit again.
+			if askYesNo(reader, "Edit again? [Y/n]: ", true, false) {
+				continue
+			}
+			fmt.Println("Discarded changes.")
+			os.Exit(1)
+		}
+
+		if err := writeFileAtomic(configPath(), edited); err != nil {
+			fmt.Fprintf(os.Stderr, "error saving config: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Saved %s\n", displayPath(configPath()))
+		return
+	}
+}
+
+// configValidate checks the config strictly and that every sound it names,
+// in the top-level settings and in profiles, exists.
+func configValidate() {
+	var problems []string
+	var ce *configError
+	cfg, err := loadConfig()
+	if errors.As(err, &ce) {
+		problems = ce.problems
+	} else if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	customSounds, err := loadCustomSounds()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "custom sounds %s: %v\n", displayPath(customSoundsPath()), err)
+		os.Exit(1)
+	}
+
+	if problems = append(problems, soundProblems(cfg, customSounds)...); len(problems) > 0 {
+		fmt.Fprintln(os.Stderr, (&configError{path: configPath(), problems: problems}).Error())
+		os.Exit(1)
+	}
+	fmt.Printf("%s is valid\n", displayPath(configPath()))
+}
+
+// configProblems lists what config validate would report for config file
+// contents: decoding problems and sounds that don't exist, checked on
+// whatever decoded. err is set only when data can't be checked at all.
+func configProblems(data []byte) ([]string, error) {
+	var cfg Config
+	var problems []string
+	if err := decodeConfig(data, &cfg); err != nil {
+		var ce *configError
+		if !errors.As(err, &ce) {
+			return nil, err
+		}
+		problems = ce.problems
+	}
+	customSounds, err := loadCustomSounds()
+	if err != nil {
+		return nil, fmt.Errorf("custom sounds %s: %v", displayPath(customSoundsPath()), err)
+	}
+	return append(problems, soundProblems(cfg, customSounds)...), nil
+}
+
+// soundProblems lists the sounds named in the top-level settings and in
+// profiles that are neither a preset nor a custom sound.
+func soundProblems(cfg Config, customSounds []CustomSound) []string {
+	var problems []string
+	check := func(prefix string, c Config) {
+		for _, event := range EventNames {
+			name := getConfigField(c, event)
+			if name == "" {
+				continue
+			}
+			if _, ok := lookupTones(event, name, customSounds); !ok {
+				problems = append(problems, fmt.Sprintf("%sevents.%s: %q is not a %s preset or custom sound", prefix, event, name, event))
+			}
+		}
+	}
+	check("", cfg)
+	for _, name := range sortedKeys(cfg.Profiles) {
+		check("profiles."+name+".", cfg.withProfile(name))
+	}
+	return problems
+}
+
+func configPaths() {
+	settings, _ := claudeSettingsPathForScope("user")
+	rows := []struct{ name, path string }{
+		{"config", configPath()},
+		{"custom sounds", customSoundsPath()},
+		{"state", statePath()},
+		{"sound cache", soundsDir()},
+		{"daemon socket", daemonSocketPath()},
+		{"claude settings", settings},
+	}
+	for _, r := range rows {
+		fmt.Printf("%-16s %s\n", r.name+":", r.path)
+	}
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := mapKeys(m)
+	sort.Strings(keys)
+	return keys
+}
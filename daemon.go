@@ -0,0 +1,272 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"time"
+)
+
+const (
+	daemonDialTimeout = 200 * time.Millisecond
+	daemonPollPeriod  = 2 * time.Second
+
+	// daemonTimeout bounds each exchange with the daemon, leaving room for
+	// the daemon to forward the event to a remote.
+	daemonTimeout = remoteTimeout + 2*time.Second
+)
+
+func daemonSocketPath() string {
+	return filepath.Join(configDir(), "daemon.sock")
+}
+
+// daemonResponse is the daemon's one-line JSON reply to a playRequest. With
+// NeedTTY set the daemon has done nothing yet and waits for the request
+// again, with the session's terminal filled in.
+type daemonResponse struct {
+	OK      bool   `json:"ok"`
+	Error   string `json:"error,omitempty"`
+	NeedTTY bool   `json:"need_tty,omitempty"`
+}
+
+// forwardToDaemon sends req to a running daemon. handled is false when no
+// daemon took the request, in which case the caller should play the sound
+// itself. Once the request is sent the daemon may already have played it, so
+// a missing reply is an error rather than a reason to play again.
+func forwardToDaemon(req playRequest) (handled bool, err error) {
+	conn, err := net.DialTimeout("unix", daemonSocketPath(), daemonDialTimeout)
+	if err != nil {
+		return false, nil
+	}
+	defer conn.Close()
+	enc, dec := json.NewEncoder(conn), json.NewDecoder(conn)
+
+	conn.SetDeadline(time.Now().Add(daemonTimeout))
+	if err := enc.Encode(req); err != nil {
+		return false, nil
+	}
+	var resp daemonResponse
+	if err := dec.Decode(&resp); err != nil {
+		return true, fmt.Errorf("no reply from daemon for %s: %v", req.Event, err)
+	}
+
+	if resp.NeedTTY {
+		// The daemon is not a descendant of the session, so it cannot find
+		// the terminal for terminal alerts itself. It has not played
+		// anything yet, so playing directly is still safe if it goes away.
+		req.TTY = sessionTTYPath()
+		conn.SetDeadline(time.Now().Add(daemonTimeout))
+		if err := enc.Encode(req); err != nil {
+			return false, nil
+		}
+		resp = daemonResponse{}
+		if err := dec.Decode(&resp); err != nil {
+			return true, fmt.Errorf("no reply from daemon for %s: %v", req.Event, err)
+		}
+	}
+	if !resp.OK {
+		return true, fmt.Errorf("%s", resp.Error)
+	}
+	return true, nil
+}
+
+// bellDaemon keeps the config and custom sounds loaded between events.
+type bellDaemon struct {
+	mu           sync.Mutex
+	cfg          Config
+	customSounds []CustomSound
+	modTimes     map[string]time.Time
+}
+
+func cmdDaemon() {
+	sock := daemonSocketPath()
+	if conn, err := net.DialTimeout("unix", sock, daemonDialTimeout); err == nil {
+		conn.Close()
+		fmt.Fprintf(os.Stderr, "error: a daemon is already listening on %s\n", displayPath(sock))
+		os.Exit(1)
+	}
+	os.Remove(sock) // left over from a daemon that did not shut down cleanly
+
+	if err := os.MkdirAll(filepath.Dir(sock), 0755); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+	ln, err := net.Listen("unix", sock)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+	os.Chmod(sock, 0600)
+
+	d := &bellDaemon{}
+	if err := d.reload(); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+	}
+
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		<-sigs
+		ln.Close()
+	}()
+
+	go d.watch()
+
+	fmt.Printf("claude-bell daemon listening on %s\n", displayPath(sock))
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			break
+		}
+		go d.serve(conn)
+	}
+	os.Remove(sock)
+}
+
+// reload reads config and custom sounds from disk.
+func (d *bellDaemon) reload() error {
+	cfg, err := loadPlayConfig()
+	if err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
+	customSounds, err := loadCustomSounds()
+	if err != nil {
+		return fmt.Errorf("loading custom sounds: %w", err)
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.cfg = cfg
+	d.customSounds = customSounds
+	d.modTimes = d.currentModTimes()
+	return nil
+}
+
+func (d *bellDaemon) currentModTimes() map[string]time.Time {
+	times := make(map[string]time.Time)
+	for _, path := range []string{configPath(), customSoundsPath()} {
+		if info, err := os.Stat(path); err == nil {
+			times[path] = info.ModTime()
+		}
+	}
+	return times
+}
+
+// watch polls the config files and reloads when either changes.
+func (d *bellDaemon) watch() {
+	for range time.Tick(daemonPollPeriod) {
+		d.mu.Lock()
+		changed := false
+		current := d.currentModTimes()
+		if len(current) != len(d.modTimes) {
+			changed = true
+		}
+		for path, t := range current {
+			if !d.modTimes[path].Equal(t) {
+				changed = true
+			}
+		}
+		d.mu.Unlock()
+
+		if changed {
+			if err := d.reload(); err != nil {
+				fmt.Fprintf(os.Stderr, "warning: %v (keeping previous config)\n", err)
+				continue
+			}
+			fmt.Println("config reloaded")
+		}
+	}
+}
+
+func (d *bellDaemon) serve(conn net.Conn) {
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(daemonTimeout))
+
+	dec, enc := json.NewDecoder(bufio.NewReader(conn)), json.NewEncoder(conn)
+	var req playRequest
+	if err := dec.Decode(&req); err != nil {
+		enc.Encode(daemonResponse{Error: fmt.Sprintf("bad request: %v", err)})
+		return
+	}
+
+	d.mu.Lock()
+	cfg := d.cfg
+	d.mu.Unlock()
+
+	remote := cfg.Remote != nil && cfg.Remote.URL != ""
+	if !remote && req.TTY == "" && wantsTerminal(cfg, req.Event) {
+		enc.Encode(daemonResponse{NeedTTY: true})
+		conn.SetDeadline(time.Now().Add(daemonTimeout))
+		if err := dec.Decode(&req); err != nil {
+			return
+		}
+	}
+
+	var err error
+	if remote {
+		err = forwardToRemote(cfg.Remote, req)
+	} else {
+		err = playEvent(cfg, req, d.play)
+	}
+	resp := daemonResponse{OK: true}
+	if err != nil {
+		resp = daemonResponse{Error: err.Error()}
+	}
+	enc.Encode(resp)
+}
+
+// wantsTerminal reports whether the top-level settings or any profile send
+// terminal alerts for event.
+func wantsTerminal(cfg Config, event string) bool {
+	if _, ok := cfg.Terminal[event]; ok {
+		return true
+	}
+	for _, name := range sortedKeys(cfg.Profiles) {
+		if _, ok := cfg.withProfile(name).Terminal[event]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// play renders the sound into the sounds cache if the file is missing and
+// starts playback in the background, so the hook returns immediately.
+func (d *bellDaemon) play(cfg Config, event, preset, phrase string, volume float64) error {
+	var path string
+	if preset != "" {
+		path = soundPath(event, preset)
+		if _, err := os.Stat(path); err != nil {
+			if err := d.render(event, preset, path); err != nil {
+				return err
+			}
+		}
+	}
+
+	go func() {
+		if err := playSerialized(cfg, event, playSteps(cfg, event, path, phrase, volume)...); err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", event, err)
+		}
+	}()
+	return nil
+}
+
+// render writes the WAV for a preset or custom sound, using the custom
+// sounds loaded by the daemon.
+func (d *bellDaemon) render(event, preset, path string) error {
+	d.mu.Lock()
+	tones, ok := lookupTones(event, preset, d.customSounds)
+	d.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("unknown preset %q for event %q", preset, event)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return writeWAV(path, renderTones(tones))
+}
@@ -0,0 +1,339 @@
+package main
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// A minimal D-Bus client: just enough of the wire protocol to call methods
+// on the session bus and wait for their replies, so desktop notifications
+// need no external library.
+
+const dbusTimeout = 2 * time.Second
+
+// D-Bus message types and header field codes.
+const (
+	dbusMethodCall   = 1
+	dbusMethodReturn = 2
+	dbusError        = 3
+
+	dbusFieldPath        = 1
+	dbusFieldInterface   = 2
+	dbusFieldMember      = 3
+	dbusFieldErrorName   = 4
+	dbusFieldReplySerial = 5
+	dbusFieldDestination = 6
+	dbusFieldSignature   = 8
+)
+
+type dbusConn struct {
+	conn   net.Conn
+	r      *bufio.Reader
+	serial uint32
+}
+
+// dbusSessionConn connects and authenticates to the session bus.
+func dbusSessionConn() (*dbusConn, error) {
+	addrs := os.Getenv("DBUS_SESSION_BUS_ADDRESS")
+	if addrs == "" {
+		if dir := os.Getenv("XDG_RUNTIME_DIR"); dir != "" {
+			addrs = "unix:path=" + dir + "/bus"
+		} else {
+			return nil, errors.New("no D-Bus session bus (DBUS_SESSION_BUS_ADDRESS is not set)")
+		}
+	}
+
+	var lastErr error
+	for _, addr := range strings.Split(addrs, ";") {
+		path, ok := dbusUnixPath(addr)
+		if !ok {
+			continue
+		}
+		conn, err := net.DialTimeout("unix", path, dbusTimeout)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		conn.SetDeadline(time.Now().Add(dbusTimeout))
+		c := &dbusConn{conn: conn, r: bufio.NewReader(conn)}
+		if err := c.auth(); err != nil {
+			conn.Close()
+			lastErr = err
+			continue
+		}
+		if _, err := c.call("org.freedesktop.DBus", "/org/freedesktop/DBus", "org.freedesktop.DBus", "Hello", "", nil); err != nil {
+			conn.Close()
+			lastErr = err
+			continue
+		}
+		return c, nil
+	}
+	if lastErr == nil {
+		lastErr = fmt.Errorf("no usable unix address in %q", addrs)
+	}
+	return nil, lastErr
+}
+
+// dbusUnixPath extracts the socket path from a unix: bus address. Abstract
+// sockets get Go's leading "@".
+func dbusUnixPath(addr string) (string, bool) {
+	rest, ok := strings.CutPrefix(addr, "unix:")
+	if !ok {
+		return "", false
+	}
+	for _, kv := range strings.Split(rest, ",") {
+		k, v, _ := strings.Cut(kv, "=")
+		switch k {
+		case "path":
+			return v, true
+		case "abstract":
+			return "@" + v, true
+		}
+	}
+	return "", false
+}
+
+func (c *dbusConn) Close() error {
+	return c.conn.Close()
+}
+
+// auth performs SASL EXTERNAL authentication with the caller's uid.
+func (c *dbusConn) auth() error {
+	uid := hex.EncodeToString([]byte(strconv.Itoa(os.Getuid())))
+	if _, err := c.conn.Write([]byte("\x00AUTH EXTERNAL " + uid + "\r\n")); err != nil {
+		return err
+	}
+	line, err := c.r.ReadString('\n')
+	if err != nil {
+		return err
+	}
+	if !strings.HasPrefix(line, "OK ") {
+		return fmt.Errorf("D-Bus authentication rejected: %s", strings.TrimSpace(line))
+	}
+	_, err = c.conn.Write([]byte("BEGIN\r\n"))
+	return err
+}
+
+// call sends a method call whose body was encoded with signature sig and
+// waits for its reply, returning the raw reply body.
+func (c *dbusConn) call(dest, path, iface, member, sig string, body []byte) ([]byte, error) {
+	c.serial++
+	serial := c.serial
+
+	var h dbusEncoder
+	h.byte('l')
+	h.byte(dbusMethodCall)
+	h.byte(0) // flags
+	h.byte(1) // protocol version
+	h.uint32(uint32(len(body)))
+	h.uint32(serial)
+
+	lenPos := h.arrayStart()
+	start := len(h.buf)
+	h.field(dbusFieldPath, "o", func() { h.string(path) })
+	h.field(dbusFieldInterface, "s", func() { h.string(iface) })
+	h.field(dbusFieldMember, "s", func() { h.string(member) })
+	h.field(dbusFieldDestination, "s", func() { h.string(dest) })
+	if sig != "" {
+		h.field(dbusFieldSignature, "g", func() { h.signature(sig) })
+	}
+	h.arrayEnd(lenPos, start)
+	h.align(8)
+
+	if _, err := c.conn.Write(append(h.buf, body...)); err != nil {
+		return nil, err
+	}
+
+	for {
+		msgType, fields, replyBody, err := c.readMessage()
+		if err != nil {
+			return nil, err
+		}
+		if fields.replySerial != serial {
+			continue // a signal, e.g. NameAcquired
+		}
+		switch msgType {
+		case dbusMethodReturn:
+			return replyBody, nil
+		case dbusError:
+			return nil, fmt.Errorf("D-Bus error %s", fields.errorName)
+		}
+	}
+}
+
+type dbusHeaderFields struct {
+	replySerial uint32
+	errorName   string
+}
+
+func (c *dbusConn) readMessage() (byte, dbusHeaderFields, []byte, error) {
+	var fields dbusHeaderFields
+	fixed := make([]byte, 16)
+	if _, err := io.ReadFull(c.r, fixed); err != nil {
+		return 0, fields, nil, err
+	}
+
+	var order binary.ByteOrder = binary.LittleEndian
+	if fixed[0] == 'B' {
+		order = binary.BigEndian
+	}
+	msgType := fixed[1]
+	bodyLen := order.Uint32(fixed[4:8])
+	fieldsLen := order.Uint32(fixed[12:16])
+
+	headerLen := 16 + int(fieldsLen)
+	padded := (headerLen + 7) &^ 7
+	rest := make([]byte, padded-16+int(bodyLen))
+	if _, err := io.ReadFull(c.r, rest); err != nil {
+		return 0, fields, nil, err
+	}
+
+	d := dbusDecoder{buf: append(fixed, rest...), order: order, pos: 16}
+	for d.pos < headerLen {
+		d.align(8)
+		code := d.byte()
+		sig := d.signature()
+		switch sig {
+		case "u":
+			v := d.uint32()
+			if code == dbusFieldReplySerial {
+				fields.replySerial = v
+			}
+		case "s", "o":
+			v := d.string()
+			if code == dbusFieldErrorName {
+				fields.errorName = v
+			}
+		case "g":
+			d.signature()
+		default:
+			return 0, fields, nil, fmt.Errorf("unexpected D-Bus header field type %q", sig)
+		}
+		if d.err != nil {
+			return 0, fields, nil, d.err
+		}
+	}
+	return msgType, fields, d.buf[padded:], nil
+}
+
+// dbusEncoder marshals values in little-endian D-Bus wire format. Offsets
+// are relative to the start of buf, which must begin 8-byte aligned.
+type dbusEncoder struct {
+	buf []byte
+}
+
+func (e *dbusEncoder) align(n int) {
+	for len(e.buf)%n != 0 {
+		e.buf = append(e.buf, 0)
+	}
+}
+
+func (e *dbusEncoder) byte(b byte) {
+	e.buf = append(e.buf, b)
+}
+
+func (e *dbusEncoder) uint32(v uint32) {
+	e.align(4)
+	e.buf = binary.LittleEndian.AppendUint32(e.buf, v)
+}
+
+func (e *dbusEncoder) int32(v int32) {
+	e.uint32(uint32(v))
+}
+
+func (e *dbusEncoder) string(s string) {
+	e.uint32(uint32(len(s)))
+	e.buf = append(e.buf, s...)
+	e.buf = append(e.buf, 0)
+}
+
+func (e *dbusEncoder) signature(s string) {
+	e.byte(byte(len(s)))
+	e.buf = append(e.buf, s...)
+	e.buf = append(e.buf, 0)
+}
+
+// arrayStart writes a placeholder length and returns its position. Callers
+// then align to the element boundary before recording the data start.
+func (e *dbusEncoder) arrayStart() int {
+	e.uint32(0)
+	return len(e.buf) - 4
+}
+
+func (e *dbusEncoder) arrayEnd(lenPos, start int) {
+	binary.LittleEndian.PutUint32(e.buf[lenPos:], uint32(len(e.buf)-start))
+}
+
+// field writes one (code, variant) header field struct.
+func (e *dbusEncoder) field(code byte, sig string, value func()) {
+	e.align(8)
+	e.byte(code)
+	e.signature(sig)
+	value()
+}
+
+type dbusDecoder struct {
+	buf   []byte
+	order binary.ByteOrder
+	pos   int
+	err   error
+}
+
+func (d *dbusDecoder) need(n int) bool {
+	if d.err == nil && d.pos+n > len(d.buf) {
+		d.err = errors.New("truncated D-Bus message")
+	}
+	return d.err == nil
+}
+
+func (d *dbusDecoder) align(n int) {
+	d.pos = (d.pos + n - 1) &^ (n - 1)
+}
+
+func (d *dbusDecoder) byte() byte {
+	if !d.need(1) {
+		return 0
+	}
+	b := d.buf[d.pos]
+	d.pos++
+	return b
+}
+
+func (d *dbusDecoder) uint32() uint32 {
+	d.align(4)
+	if !d.need(4) {
+		return 0
+	}
+	v := d.order.Uint32(d.buf[d.pos:])
+	d.pos += 4
+	return v
+}
+
+func (d *dbusDecoder) string() string {
+	n := int(d.uint32())
+	if !d.need(n + 1) {
+		return ""
+	}
+	s := string(d.buf[d.pos : d.pos+n])
+	d.pos += n + 1
+	return s
+}
+
+func (d *dbusDecoder) signature() string {
+	n := int(d.byte())
+	if !d.need(n + 1) {
+		return ""
+	}
+	s := string(d.buf[d.pos : d.pos+n])
+	d.pos += n + 1
+	return s
+}
@@ -0,0 +1,231 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// doctorReport collects check results and prints them as they are added.
+type doctorReport struct {
+	failures int
+	warnings int
+}
+
+func (r *doctorReport) pass(format string, args ...any) {
+	fmt.Printf("  [ok]   %s\n", fmt.Sprintf(format, args...))
+}
+
+func (r *doctorReport) warn(hint, format string, args ...any) {
+	r.warnings++
+	fmt.Printf("  [warn] %s\n", doctorIndent(fmt.Sprintf(format, args...)))
+	if hint != "" {
+		fmt.Printf("         -> %s\n", hint)
+	}
+}
+
+// doctorIndent aligns the continuation lines of a multi-line message with
+// its first line.
+func doctorIndent(msg string) string {
+	return strings.ReplaceAll(msg, "\n", "\n         ")
+}
+
+func (r *doctorReport) fail(hint, format string, args ...any) {
+	r.failures++
+	fmt.Printf("  [FAIL] %s\n", doctorIndent(fmt.Sprintf(format, args...)))
+	if hint != "" {
+		fmt.Printf("         -> %s\n", hint)
+	}
+}
+
+func cmdDoctor() {
+	r := &doctorReport{}
+
+	fmt.Println("claude-bell doctor")
+	fmt.Println()
+
+	fmt.Println("Configuration")
+	cfg, cfgErr := loadConfig()
+	if cfgErr != nil {
+		r.fail("fix the config by hand or delete it and rerun 'claude-bell setup'", "%v", cfgErr)
+	} else {
+		r.pass("config %s parses", displayPath(configPath()))
+	}
+
+	customSounds, csErr := loadCustomSounds()
+	if csErr != nil {
+		r.fail("fix the JSON by hand or delete the file and recreate the sounds",
+			"custom sounds %s: %v", displayPath(customSoundsPath()), csErr)
+	} else {
+		r.pass("custom sounds parse (%d defined)", len(customSounds))
+	}
+
+	if cfgErr == nil {
+		// "" stands for the top-level settings.
+		for _, profile := range append([]string{""}, sortedKeys(cfg.Profiles)...) {
+			settings, where := cfg, ""
+			if profile != "" {
+				settings, where = cfg.withProfile(profile), " in profile "+profile
+			}
+			for _, event := range EventNames {
+				preset := getConfigField(settings, event)
+				if preset == "" {
+					continue
+				}
+				if _, ok := lookupTones(event, preset, customSounds); ok {
+					r.pass("%s sound %q%s exists", event, preset, where)
+				} else {
+					r.fail("pick another sound with 'claude-bell setup'",
+						"%s sound %q%s is not a preset or custom sound", event, preset, where)
+				}
+			}
+		}
+		if len(hookedEvents(cfg)) == 0 {
+			r.fail("run 'claude-bell setup'", "no sounds configured")
+		}
+	}
+	fmt.Println()
+
+	fmt.Println("Hooks")
+	checkDoctorHooks(r, cfg)
+	fmt.Println()
+
+	fmt.Println("Audio")
+	checkDoctorCache(r)
+	if backend, bin, err := findAudioBackend(); err != nil {
+		r.fail("on Linux install pipewire (pw-play) or pulseaudio-utils (paplay)", "%v", err)
+	} else {
+		r.pass("audio backend %s (%s)", backend.name, bin)
+	}
+	for _, event := range EventNames {
+		sc, ok := cfg.Speak[event]
+		if !ok {
+			continue
+		}
+		if engine, bin, err := findSpeechEngine(sc.Engine); err != nil {
+			r.fail("install espeak-ng, or set \"engine\" to one that is installed", "%s speech: %v", event, err)
+		} else {
+			r.pass("%s speech engine %s (%s)", event, engine.name, bin)
+		}
+	}
+	checkDoctorRender(r)
+	fmt.Println()
+
+	switch {
+	case r.failures > 0:
+		fmt.Printf("%d problem(s) found, %d warning(s).\n", r.failures, r.warnings)
+		os.Exit(1)
+	case r.warnings > 0:
+		fmt.Printf("No problems found, %d warning(s).\n", r.warnings)
+	default:
+		fmt.Println("Everything looks good.")
+	}
+}
+
+// checkDoctorHooks inspects every settings scope for claude-bell hooks and
+// checks that each configured event has a working one.
+func checkDoctorHooks(r *doctorReport, cfg Config) {
+	exePath, _ := executablePath()
+	covered := make(map[string]bool)
+	var seen []string
+
+	for _, scope := range settingsScopes {
+		path, err := claudeSettingsPathForScope(scope)
+		if err != nil {
+			continue
+		}
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			continue
+		}
+		// Outside a repository the project file can be the user one.
+		if slices.ContainsFunc(seen, func(p string) bool { return samePath(p, path) }) {
+			continue
+		}
+		seen = append(seen, path)
+		doc, err := loadSettingsDoc(path)
+		if err != nil {
+			r.fail("fix the JSON or run 'claude-bell restore'", "%s: %v", displayPath(path), err)
+			continue
+		}
+
+		hooks, problems := findBellHooks(doc.hooks)
+		for _, p := range problems {
+			r.fail(fmt.Sprintf("rerun 'claude-bell install --scope %s'", scope), "%s: %s", displayPath(path), p)
+		}
+		for _, h := range hooks {
+			label := fmt.Sprintf("%s: %s hook runs %s", displayPath(path), h.hookType, h.binary)
+			resolved, err := resolveHookBinary(h.binary)
+			switch {
+			case err != nil:
+				r.fail(fmt.Sprintf("rerun 'claude-bell install --scope %s'", scope), "%s, which does not exist", label)
+				continue
+			case !sameFile(resolved, exePath):
+				r.warn(fmt.Sprintf("rerun 'claude-bell install --scope %s' to use %s", scope, exePath),
+					"%s, not this binary (%s)", label, exePath)
+			default:
+				r.pass("%s", label)
+			}
+			covered[h.event] = true
+		}
+	}
+
+	// Hooks installed before CLAUDE_CONFIG_DIR was set are never read.
+	if home, err := os.UserHomeDir(); err == nil {
+		legacy := filepath.Join(home, ".claude", "settings.json")
+		if !samePath(legacy, claudeSettingsPath()) {
+			if doc, err := loadSettingsDoc(legacy); err == nil {
+				if hooks, _ := findBellHooks(doc.hooks); len(hooks) > 0 {
+					r.warn("run 'claude-bell install' and remove the old hooks from "+displayPath(legacy),
+						"%s has claude-bell hooks, but CLAUDE_CONFIG_DIR points Claude Code at %s",
+						displayPath(legacy), displayPath(claudeConfigDir()))
+				}
+			}
+		}
+	}
+
+	for _, event := range hookedEvents(cfg) {
+		if !covered[event] {
+			r.fail("run 'claude-bell install'", "no hook installed for %s", event)
+		}
+	}
+}
+
+func checkDoctorCache(r *doctorReport) {
+	dir := soundsDir()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		r.fail("check permissions on "+filepath.Dir(dir), "sounds cache %s: %v", displayPath(dir), err)
+		return
+	}
+	f, err := os.CreateTemp(dir, ".doctor-*")
+	if err != nil {
+		r.fail("check permissions on "+dir, "sounds cache %s is not writable: %v", displayPath(dir), err)
+		return
+	}
+	f.Close()
+	os.Remove(f.Name())
+	r.pass("sounds cache %s is writable", displayPath(dir))
+}
+
+func checkDoctorRender(r *doctorReport) {
+	tones := EventPresets["stop"][0].Tones
+	f, err := os.CreateTemp("", "claude-bell-doctor-*.wav")
+	if err != nil {
+		r.fail("", "test render: %v", err)
+		return
+	}
+	f.Close()
+	defer os.Remove(f.Name())
+
+	if err := generateWAV(f.Name(), tones); err != nil {
+		r.fail("", "test render: %v", err)
+		return
+	}
+	info, err := os.Stat(f.Name())
+	if err != nil || info.Size() <= 44 {
+		r.fail("", "test render produced an empty WAV file")
+		return
+	}
+	r.pass("test render succeeded (%d bytes)", info.Size())
+}
@@ -1,10 +1,15 @@
 package main
 
 import (
-	"encoding/json"
+	"bufio"
+	"errors"
+	"flag"
 	"fmt"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"slices"
+	"strings"
 )
 
 // hookDef defines how an event maps to a Claude Code hook.
@@ -21,187 +26,538 @@ var hookDefs = []hookDef{
 }
 
 func cmdInstall() {
+	fs := flag.NewFlagSet("install", flag.ExitOnError)
+	scope := fs.String("scope", "user", "settings file to write: user, project, or local")
+	dryRun := fs.Bool("dry-run", false, "print the settings diff without writing anything")
+	yes := fs.Bool("yes", false, "apply without asking for confirmation")
+	pathLookup := fs.Bool("path-lookup", false, "run hooks as 'claude-bell' found on PATH instead of an absolute path")
+	fs.Parse(os.Args[2:])
+
+	opts := installOptions{scope: *scope, dryRun: *dryRun, yes: *yes}
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == "path-lookup" {
+			opts.pathLookup = pathLookup
+		}
+	})
+	installHooks(opts)
+}
+
+// installOptions are the settings for installHooks. A nil pathLookup keeps
+// whatever the current hooks use.
+type installOptions struct {
+	scope      string
+	dryRun     bool
+	yes        bool
+	pathLookup *bool
+}
+
+// installHooks adds or updates the bell hooks in the Claude Code settings
+// for opts.scope, exiting on errors.
+func installHooks(opts installOptions) {
+	settingsPath, err := claudeSettingsPathForScope(opts.scope)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+
 	cfg, err := loadConfig()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "error loading config: %v\n", err)
 		os.Exit(1)
 	}
 
-	if cfg.Stop == "" && cfg.Notification == "" && cfg.Limit == "" {
+	events := hookedEvents(cfg)
+	if len(events) == 0 {
 		fmt.Println("No sounds configured. Run 'claude-bell setup' first.")
 		return
 	}
 
+	doc, err := loadSettingsDoc(settingsPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error loading settings: %v\n", err)
+		os.Exit(1)
+	}
+
+	existing, _ := findBellHooks(doc.hooks)
+
+	// Project settings are shared with the team, so they use PATH lookup
+	// rather than this machine's path. Elsewhere, keep PATH lookup if the
+	// current hooks already use it, unless the flag says otherwise.
+	pathLookup := opts.scope == "project"
+	if opts.pathLookup != nil {
+		pathLookup = *opts.pathLookup
+	} else if len(existing) > 0 && !pathLookup {
+		pathLookup = true
+		for _, h := range existing {
+			if h.binary != pathLookupBinary {
+				pathLookup = false
+			}
+		}
+	}
+
 	exePath, isTemp := executablePath()
-	if isTemp {
+	hookBinary := exePath
+	if pathLookup {
+		hookBinary = pathLookupBinary
+		resolved, err := exec.LookPath(pathLookupBinary)
+		if err != nil {
+			fmt.Printf("WARNING: %q is not on PATH; hooks will fail until it is.\n", pathLookupBinary)
+			fmt.Println()
+		} else if !sameFile(resolved, exePath) {
+			fmt.Printf("NOTE: hooks will run %s, found first on PATH.\n", resolved)
+			fmt.Println()
+		}
+	} else if opts.scope == "project" {
+		fmt.Printf("WARNING: hooks will run %s, which teammates sharing these settings may not have.\n", exePath)
+		fmt.Println()
+	} else if isTemp {
 		fmt.Println("WARNING: claude-bell is running from a temporary path (go run).")
 		fmt.Println("Hooks will not work after this process exits.")
 		fmt.Println("Build and install first: go build -o claude-bell . && sudo mv claude-bell /usr/local/bin/")
 		fmt.Println()
 	}
 
-	settingsPath := claudeSettingsPath()
+	var stale []string
+	for _, h := range existing {
+		if h.binary != hookBinary && !slices.Contains(stale, h.binary) {
+			stale = append(stale, h.binary)
+		}
+	}
 
-	settings, err := loadSettingsJSON(settingsPath)
+	installBellHooks(doc.Hooks(), events, hookBinary)
+	updated, err := doc.Bytes()
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "error loading settings: %v\n", err)
+		fmt.Fprintf(os.Stderr, "error encoding settings: %v\n", err)
 		os.Exit(1)
 	}
 
-	if err := backupSettings(settingsPath); err != nil {
-		fmt.Fprintf(os.Stderr, "warning: could not create backup: %v\n", err)
+	name := displayPath(settingsPath)
+	if opts.dryRun {
+		printSettingsDiff(name, doc.raw, updated)
+
+		// Show that uninstall would cleanly revert what install adds.
+		after, err := parseSettingsDoc(updated)
+		if err == nil {
+			removeBellHooks(after)
+			if reverted, err := after.Bytes(); err == nil {
+				fmt.Println()
+				fmt.Println("A later 'claude-bell uninstall' would then change:")
+				printSettingsDiff(name, updated, reverted)
+			}
+		}
+		return
 	}
 
-	hooks, _ := settings["hooks"].(map[string]any)
-	if hooks == nil {
-		hooks = make(map[string]any)
+	if string(updated) == string(doc.raw) {
+		fmt.Printf("Hooks in %s are already up to date.\n", name)
+		return
 	}
 
-	for _, hd := range hookDefs {
-		preset := getConfigField(cfg, hd.event)
-		if preset == "" {
-			continue
-		}
-
-		hookKey := hd.hookType
-		existing, _ := hooks[hookKey].([]any)
-
-		var filtered []any
-		for _, entry := range existing {
-			m, ok := entry.(map[string]any)
-			if !ok {
-				filtered = append(filtered, entry)
-				continue
-			}
-			if _, isBell := m["_claude_bell"]; isBell {
-				continue
-			}
-			filtered = append(filtered, entry)
-		}
-
-		newEntry := map[string]any{
-			"_claude_bell": true,
-			"matcher":      hd.matcher,
-			"hooks": []any{
-				map[string]any{
-					"type":    "command",
-					"command": fmt.Sprintf("%s play %s", exePath, hd.event),
-					"async":   true,
-				},
-			},
+	if !opts.yes && isTerminal(os.Stdin) {
+		printSettingsDiff(name, doc.raw, updated)
+		fmt.Println()
+		if !promptYesNo(bufio.NewReader(os.Stdin), "Apply these changes? [Y/n]: ", true) {
+			fmt.Println("No changes made.")
+			return
 		}
-
-		filtered = append(filtered, newEntry)
-		hooks[hookKey] = filtered
 	}
 
-	settings["hooks"] = hooks
+	if err := backupSettings(settingsPath, cfg.BackupKeep); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: could not create backup: %v\n", err)
+	}
 
-	if err := writeSettingsJSON(settingsPath, settings); err != nil {
+	if err := writeFileAtomic(settingsPath, updated); err != nil {
 		fmt.Fprintf(os.Stderr, "error writing settings: %v\n", err)
 		os.Exit(1)
 	}
 
-	fmt.Println("Hooks installed into ~/.claude/settings.json")
+	fmt.Printf("Hooks installed into %s\n", name)
+	for _, old := range stale {
+		fmt.Printf("Updated hooks that ran %s\n", old)
+	}
 	fmt.Println()
 	for _, hd := range hookDefs {
+		if !slices.Contains(events, hd.event) {
+			continue
+		}
 		preset := getConfigField(cfg, hd.event)
 		if preset == "" {
-			continue
+			preset = "(no top-level sound)"
 		}
 		fmt.Printf("  %s (%s): %s\n", hd.event, hd.hookType, preset)
 	}
 }
 
 func cmdUninstall() {
-	settingsPath := claudeSettingsPath()
+	fs := flag.NewFlagSet("uninstall", flag.ExitOnError)
+	scope := fs.String("scope", "user", "settings file to edit: user, project, or local")
+	dryRun := fs.Bool("dry-run", false, "print the settings diff without writing anything")
+	yes := fs.Bool("yes", false, "apply without asking for confirmation")
+	fs.Parse(os.Args[2:])
+
+	settingsPath, err := claudeSettingsPathForScope(*scope)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
 
-	settings, err := loadSettingsJSON(settingsPath)
+	doc, err := loadSettingsDoc(settingsPath)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "error loading settings: %v\n", err)
 		os.Exit(1)
 	}
 
-	hooks, _ := settings["hooks"].(map[string]any)
-	if hooks == nil {
+	if doc.hooks == nil {
 		fmt.Println("No hooks found in settings.")
 		return
 	}
 
+	removed := removeBellHooks(doc)
+	if removed == 0 {
+		fmt.Println("No claude-bell hooks found.")
+		return
+	}
+
+	updated, err := doc.Bytes()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error encoding settings: %v\n", err)
+		os.Exit(1)
+	}
+
+	name := displayPath(settingsPath)
+	if *dryRun {
+		printSettingsDiff(name, doc.raw, updated)
+		return
+	}
+
+	if !*yes && isTerminal(os.Stdin) {
+		printSettingsDiff(name, doc.raw, updated)
+		fmt.Println()
+		if !promptYesNo(bufio.NewReader(os.Stdin), "Apply these changes? [Y/n]: ", true) {
+			fmt.Println("No changes made.")
+			return
+		}
+	}
+
+	cfg, _ := loadConfig()
+	if err := backupSettings(settingsPath, cfg.BackupKeep); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: could not create backup: %v\n", err)
+	}
+
+	if err := writeFileAtomic(settingsPath, updated); err != nil {
+		fmt.Fprintf(os.Stderr, "error writing settings: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("Removed %d claude-bell hook(s) from %s\n", removed, name)
+}
+
+// pathLookupBinary is the hook command name used with install --path-lookup.
+const pathLookupBinary = "claude-bell"
+
+// installBellHooks replaces any claude-bell hooks with fresh ones for
+// events, each running binary. When the user already has a matcher
+// group for the same hook type and matcher, the command joins that group;
+// otherwise claude-bell adds a group of its own.
+func installBellHooks(hooks *jsonObject, events []string, binary string) {
+	for _, hd := range hookDefs {
+		if !slices.Contains(events, hd.event) {
+			continue
+		}
+
+		hookKey := hd.hookType
+		existingVal, _ := hooks.Get(hookKey)
+		existing, _ := existingVal.([]any)
+		filtered, _ := stripBellHooks(existing)
+
+		command := newJSONObject().
+			Set("type", "command").
+			Set("command", fmt.Sprintf("%s play %s", binary, hd.event)).
+			Set("async", true)
+
+		if group := findMatcherGroup(filtered, hd.matcher); group != nil {
+			inner, _ := group.Get("hooks")
+			cmds, _ := inner.([]any)
+			group.Set("hooks", append(cmds, command.Set("_claude_bell", true)))
+		} else {
+			filtered = append(filtered, newJSONObject().
+				Set("_claude_bell", true).
+				Set("matcher", hd.matcher).
+				Set("hooks", []any{command}))
+		}
+		hooks.Set(hookKey, filtered)
+	}
+}
+
+// findMatcherGroup returns the user's matcher group with the given matcher,
+// treating a missing matcher as "".
+func findMatcherGroup(entries []any, matcher string) *jsonObject {
+	for _, entry := range entries {
+		group, ok := entry.(*jsonObject)
+		if !ok || isBellEntry(entry) {
+			continue
+		}
+		inner, _ := group.Get("hooks")
+		if _, ok := inner.([]any); !ok {
+			continue
+		}
+		m, _ := group.Get("matcher")
+		ms, _ := m.(string)
+		if ms == matcher {
+			return group
+		}
+	}
+	return nil
+}
+
+// stripBellHooks removes claude-bell commands from entries, returning what
+// is left and how many commands were removed. Commands users added to a
+// claude-bell matcher group stay, and the group loses its marker; a group
+// left with no commands is dropped.
+func stripBellHooks(entries []any) ([]any, int) {
+	var filtered []any
 	removed := 0
-	for key, val := range hooks {
-		entries, ok := val.([]any)
+	for _, entry := range entries {
+		group, ok := entry.(*jsonObject)
 		if !ok {
+			filtered = append(filtered, entry)
 			continue
 		}
-		var filtered []any
-		for _, entry := range entries {
-			m, ok := entry.(map[string]any)
-			if !ok {
+		ownGroup := isBellEntry(entry)
+		inner, _ := group.Get("hooks")
+		cmds, ok := inner.([]any)
+		if !ok {
+			if ownGroup {
+				removed++
+			} else {
 				filtered = append(filtered, entry)
-				continue
 			}
-			// Check for _claude_bell marker (new nested format)
-			if _, isBell := m["_claude_bell"]; isBell {
+			continue
+		}
+		var kept []any
+		for _, c := range cmds {
+			if isBellCommand(c, ownGroup) {
 				removed++
 				continue
 			}
+			kept = append(kept, c)
+		}
+		if len(kept) == len(cmds) && !ownGroup {
 			filtered = append(filtered, entry)
+			continue
 		}
+		if len(kept) == 0 {
+			continue
+		}
+		group.Delete("_claude_bell")
+		group.Set("hooks", kept)
+		filtered = append(filtered, group)
+	}
+	return filtered, removed
+}
+
+// removeBellHooks drops every claude-bell hook from the document and returns
+// how many were removed. Hook types left empty are deleted.
+func removeBellHooks(doc *settingsDoc) int {
+	if doc.hooks == nil {
+		return 0
+	}
+
+	hooks := doc.hooks
+	removed := 0
+	for _, key := range append([]string(nil), hooks.Keys()...) {
+		val, _ := hooks.Get(key)
+		entries, ok := val.([]any)
+		if !ok {
+			continue
+		}
+		filtered, n := stripBellHooks(entries)
+		if n == 0 {
+			continue
+		}
+		removed += n
 		if len(filtered) == 0 {
-			delete(hooks, key)
+			hooks.Delete(key)
 		} else {
-			hooks[key] = filtered
+			hooks.Set(key, filtered)
 		}
 	}
+	return removed
+}
 
-	if len(hooks) == 0 {
-		delete(settings, "hooks")
-	} else {
-		settings["hooks"] = hooks
+// installedHook is a claude-bell command found in a settings file.
+type installedHook struct {
+	hookType string
+	matcher  string
+	command  string
+	binary   string // executable the command runs
+	event    string // event passed to play
+}
+
+// findBellHooks returns every claude-bell command in hooks, whether in its
+// own matcher group or inside a user group, along with a description of each
+// entry that is not well-formed.
+func findBellHooks(hooks *jsonObject) ([]installedHook, []string) {
+	var found []installedHook
+	var problems []string
+	if hooks == nil {
+		return nil, nil
 	}
 
-	if removed == 0 {
-		fmt.Println("No claude-bell hooks found.")
+	for _, key := range hooks.Keys() {
+		val, _ := hooks.Get(key)
+		entries, _ := val.([]any)
+		for _, entry := range entries {
+			group, ok := entry.(*jsonObject)
+			if !ok {
+				continue
+			}
+			ownGroup := isBellEntry(entry)
+			matcher, _ := group.Get("matcher")
+			matcherStr, _ := matcher.(string)
+			inner, _ := group.Get("hooks")
+			cmds, ok := inner.([]any)
+			if !ok || len(cmds) == 0 {
+				if ownGroup {
+					problems = append(problems, fmt.Sprintf("%s entry has no hooks array", key))
+				}
+				continue
+			}
+			for _, c := range cmds {
+				if !isBellCommand(c, ownGroup) {
+					continue
+				}
+				obj, ok := c.(*jsonObject)
+				if !ok {
+					problems = append(problems, fmt.Sprintf("%s entry has a non-object hook", key))
+					continue
+				}
+				typ, _ := obj.Get("type")
+				cmdVal, _ := obj.Get("command")
+				command, _ := cmdVal.(string)
+				binary, event, ok := parseBellCommand(command)
+				if typ != "command" || !ok {
+					problems = append(problems, fmt.Sprintf("%s entry has malformed command %q", key, command))
+					continue
+				}
+				found = append(found, installedHook{
+					hookType: key,
+					matcher:  matcherStr,
+					command:  command,
+					binary:   binary,
+					event:    event,
+				})
+			}
+		}
+	}
+	return found, problems
+}
+
+// parseBellCommand splits a "<binary> play <event>" hook command.
+func parseBellCommand(command string) (binary, event string, ok bool) {
+	idx := strings.LastIndex(command, " play ")
+	if idx <= 0 {
+		return "", "", false
+	}
+	binary = command[:idx]
+	event = strings.TrimSpace(command[idx+len(" play "):])
+	return binary, event, event != ""
+}
+
+// resolveHookBinary returns the file a hook binary refers to, searching PATH
+// for bare command names.
+func resolveHookBinary(binary string) (string, error) {
+	if filepath.IsAbs(binary) {
+		_, err := os.Stat(binary)
+		return binary, err
+	}
+	return exec.LookPath(binary)
+}
+
+// sameFile reports whether two paths name the same file.
+func sameFile(a, b string) bool {
+	ai, err := os.Stat(a)
+	if err != nil {
+		return false
+	}
+	bi, err := os.Stat(b)
+	if err != nil {
+		return false
+	}
+	return os.SameFile(ai, bi)
+}
+
+// printSettingsDiff prints a unified diff between two versions of a settings file.
+func printSettingsDiff(name string, before, after []byte) {
+	diff := unifiedDiff(name, name+" (new)", before, after)
+	if diff == "" {
+		fmt.Printf("No changes to %s.\n", name)
 		return
 	}
+	fmt.Print(diff)
+}
 
-	if err := writeSettingsJSON(settingsPath, settings); err != nil {
-		fmt.Fprintf(os.Stderr, "error writing settings: %v\n", err)
-		os.Exit(1)
+// isBellEntry reports whether a matcher group or hook command carries the
+// claude-bell marker.
+func isBellEntry(entry any) bool {
+	m, ok := entry.(*jsonObject)
+	if !ok {
+		return false
 	}
+	_, isBell := m.Get("_claude_bell")
+	return isBell
+}
 
-	fmt.Printf("Removed %d claude-bell hook(s) from ~/.claude/settings.json\n", removed)
+// isBellCommand reports whether a hook command is claude-bell's: marked
+// itself, or shaped like "<binary> play <event>" inside a claude-bell
+// matcher group. Anything else in our groups was added by the user.
+func isBellCommand(c any, ownGroup bool) bool {
+	if isBellEntry(c) {
+		return true
+	}
+	obj, ok := c.(*jsonObject)
+	if !ok || !ownGroup {
+		return false
+	}
+	cmdVal, _ := obj.Get("command")
+	command, _ := cmdVal.(string)
+	_, _, ok = parseBellCommand(command)
+	return ok
 }
 
-func loadSettingsJSON(path string) (map[string]any, error) {
+func loadSettingsDoc(path string) (*settingsDoc, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
 		if os.IsNotExist(err) {
-			if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
-				return nil, err
-			}
-			return make(map[string]any), nil
+			return parseSettingsDoc(nil)
 		}
 		return nil, err
 	}
 
-	var settings map[string]any
-	if err := json.Unmarshal(data, &settings); err != nil {
+	doc, err := parseSettingsDoc(data)
+	if errors.Is(err, errSettingsShape) {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if err != nil {
 		return nil, fmt.Errorf("invalid JSON in %s: %w", path, err)
 	}
-	return settings, nil
+	return doc, nil
 }
 
-func writeSettingsJSON(path string, settings map[string]any) error {
-	data, err := json.MarshalIndent(settings, "", "  ")
-	if err != nil {
+// writeFileAtomic replaces path with data via a temp file and rename, keeping
+// the permissions of the file it replaces.
+func writeFileAtomic(path string, data []byte) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
 		return err
 	}
-	data = append(data, '\n')
 
-	dir := filepath.Dir(path)
-	tmp, err := os.CreateTemp(dir, ".settings-*.json")
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+"-*")
 	if err != nil {
 		return err
 	}
@@ -216,18 +572,10 @@ func writeSettingsJSON(path string, settings map[string]any) error {
 		os.Remove(tmpName)
 		return err
 	}
-
-	return os.Rename(tmpName, path)
-}
-
-func backupSettings(path string) error {
-	data, err := os.ReadFile(path)
-	if err != nil {
-		if os.IsNotExist(err) {
-			return nil
-		}
+	if err := os.Chmod(tmpName, mode); err != nil {
+		os.Remove(tmpName)
 		return err
 	}
-	backupPath := path + ".claude-bell-backup"
-	return os.WriteFile(backupPath, data, 0644)
+
+	return os.Rename(tmpName, path)
 }
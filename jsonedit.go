@@ -0,0 +1,361 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// jsonObject is a decoded JSON object that remembers the order of its keys,
+// so re-encoding it does not shuffle what the user wrote.
+type jsonObject struct {
+	keys   []string
+	values map[string]any
+}
+
+func newJSONObject() *jsonObject {
+	return &jsonObject{values: make(map[string]any)}
+}
+
+func (o *jsonObject) Get(key string) (any, bool) {
+	v, ok := o.values[key]
+	return v, ok
+}
+
+// Set updates key in place, or appends it when it is new.
+func (o *jsonObject) Set(key string, v any) *jsonObject {
+	if _, ok := o.values[key]; !ok {
+		o.keys = append(o.keys, key)
+	}
+	o.values[key] = v
+	return o
+}
+
+func (o *jsonObject) Delete(key string) {
+	if _, ok := o.values[key]; !ok {
+		return
+	}
+	delete(o.values, key)
+	for i, k := range o.keys {
+		if k == key {
+			o.keys = append(o.keys[:i], o.keys[i+1:]...)
+			break
+		}
+	}
+}
+
+func (o *jsonObject) Keys() []string {
+	return o.keys
+}
+
+func (o *jsonObject) Len() int {
+	return len(o.keys)
+}
+
+// decodeOrderedJSON decodes a single JSON value. Objects become *jsonObject,
+// arrays []any and numbers json.Number so they round-trip unchanged.
+func decodeOrderedJSON(data []byte) (any, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	v, err := decodeOrderedValue(dec)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, fmt.Errorf("unexpected data after JSON value")
+	}
+	return v, nil
+}
+
+func decodeOrderedValue(dec *json.Decoder) (any, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch t := tok.(type) {
+	case json.Delim:
+		switch t {
+		case '{':
+			obj := newJSONObject()
+			for dec.More() {
+				keyTok, err := dec.Token()
+				if err != nil {
+					return nil, err
+				}
+				v, err := decodeOrderedValue(dec)
+				if err != nil {
+					return nil, err
+				}
+				obj.Set(keyTok.(string), v)
+			}
+			if _, err := dec.Token(); err != nil {
+				return nil, err
+			}
+			return obj, nil
+		case '[':
+			arr := []any{}
+			for dec.More() {
+				v, err := decodeOrderedValue(dec)
+				if err != nil {
+					return nil, err
+				}
+				arr = append(arr, v)
+			}
+			if _, err := dec.Token(); err != nil {
+				return nil, err
+			}
+			return arr, nil
+		}
+		return nil, fmt.Errorf("unexpected %q", t)
+	default:
+		return tok, nil
+	}
+}
+
+// encodeOrderedJSON encodes v using prefix and indent like json.MarshalIndent.
+// An empty indent produces compact output.
+func encodeOrderedJSON(v any, prefix, indent string) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := appendOrderedJSON(&buf, v, prefix, indent); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func appendOrderedJSON(buf *bytes.Buffer, v any, prefix, indent string) error {
+	newline := func(depth string) {
+		if indent != "" {
+			buf.WriteByte('\n')
+			buf.WriteString(depth)
+		}
+	}
+	colon := ":"
+	if indent != "" {
+		colon = ": "
+	}
+
+	switch t := v.(type) {
+	case *jsonObject:
+		if t.Len() == 0 {
+			buf.WriteString("{}")
+			return nil
+		}
+		buf.WriteByte('{')
+		for i, k := range t.keys {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			newline(prefix + indent)
+			if err := appendScalarJSON(buf, k); err != nil {
+				return err
+			}
+			buf.WriteString(colon)
+			if err := appendOrderedJSON(buf, t.values[k], prefix+indent, indent); err != nil {
+				return err
+			}
+		}
+		newline(prefix)
+		buf.WriteByte('}')
+	case []any:
+		if len(t) == 0 {
+			buf.WriteString("[]")
+			return nil
+		}
+		buf.WriteByte('[')
+		for i, elem := range t {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			newline(prefix + indent)
+			if err := appendOrderedJSON(buf, elem, prefix+indent, indent); err != nil {
+				return err
+			}
+		}
+		newline(prefix)
+		buf.WriteByte(']')
+	default:
+		return appendScalarJSON(buf, v)
+	}
+	return nil
+}
+
+func appendScalarJSON(buf *bytes.Buffer, v any) error {
+	var out bytes.Buffer
+	enc := json.NewEncoder(&out)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return err
+	}
+	buf.Write(bytes.TrimSuffix(out.Bytes(), []byte("\n")))
+	return nil
+}
+
+// jsonMember records where a top-level member sits in the original bytes.
+type jsonMember struct {
+	key      string
+	keyStart int
+	valStart int
+	valEnd   int
+}
+
+// settingsDoc is a Claude settings file edited in place. Only the top-level
+// "hooks" member is decoded and re-encoded; every other byte of the file is
+// written back exactly as it was read.
+type settingsDoc struct {
+	raw     []byte // original contents, nil when the file did not exist
+	members []jsonMember
+	indent  string // indentation unit detected from the file, "" if compact
+	hooks   *jsonObject
+}
+
+// errSettingsShape reports a settings file that is valid JSON but not shaped
+// like Claude Code settings.
+var errSettingsShape = errors.New("unexpected settings layout")
+
+// parseSettingsDoc splits a settings file into its top-level members. A null
+// "hooks" member counts as no hooks; Bytes replaces it with the installed
+// hooks, or drops it like an empty hooks object.
+func parseSettingsDoc(data []byte) (*settingsDoc, error) {
+	doc := &settingsDoc{raw: data, indent: "  "}
+	if len(bytes.TrimSpace(data)) == 0 {
+		doc.raw = nil
+		return doc, nil
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	if d, ok := tok.(json.Delim); !ok || d != '{' {
+		return nil, fmt.Errorf("%w: top-level value is not an object", errSettingsShape)
+	}
+
+	for dec.More() {
+		before := int(dec.InputOffset())
+		keyTok, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		var value json.RawMessage
+		if err := dec.Decode(&value); err != nil {
+			return nil, err
+		}
+		m := jsonMember{
+			key:      keyTok.(string),
+			keyStart: before + bytes.IndexByte(data[before:], '"'),
+			valEnd:   int(dec.InputOffset()),
+		}
+		m.valStart = m.valEnd - len(value)
+		doc.members = append(doc.members, m)
+
+		if m.key == "hooks" {
+			v, err := decodeOrderedJSON(value)
+			if err != nil {
+				return nil, err
+			}
+			if v == nil {
+				continue
+			}
+			obj, ok := v.(*jsonObject)
+			if !ok {
+				return nil, fmt.Errorf(`%w: "hooks" is not an object`, errSettingsShape)
+			}
+			doc.hooks = obj
+		}
+	}
+	if _, err := dec.Token(); err != nil {
+		return nil, err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, fmt.Errorf("unexpected data after settings object")
+	}
+
+	if len(doc.members) > 0 {
+		lead := string(data[:doc.members[0].keyStart])
+		if nl := strings.LastIndexByte(lead, '\n'); nl >= 0 {
+			doc.indent = lead[nl+1:]
+		} else {
+			doc.indent = ""
+		}
+	}
+	return doc, nil
+}
+
+// Hooks returns the decoded hooks object, creating an empty one if needed.
+func (d *settingsDoc) Hooks() *jsonObject {
+	if d.hooks == nil {
+		d.hooks = newJSONObject()
+	}
+	return d.hooks
+}
+
+// Bytes renders the document. An empty hooks object removes the member.
+func (d *settingsDoc) Bytes() ([]byte, error) {
+	keep := d.hooks != nil && d.hooks.Len() > 0
+
+	idx := -1
+	for i, m := range d.members {
+		if m.key == "hooks" {
+			idx = i
+		}
+	}
+
+	var encoded []byte
+	if keep {
+		var err error
+		encoded, err = encodeOrderedJSON(d.hooks, d.indent, d.indent)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	sep, colon := ",\n"+d.indent, ": "
+	if d.indent == "" {
+		sep, colon = ",", ":"
+	}
+
+	var out []byte
+	switch {
+	case len(d.members) == 0:
+		if !keep {
+			return d.raw, nil
+		}
+		out = append(out, "{\n"+d.indent+`"hooks"`+colon...)
+		out = append(out, encoded...)
+		out = append(out, "\n}\n"...)
+	case idx >= 0 && keep:
+		m := d.members[idx]
+		out = append(out, d.raw[:m.valStart]...)
+		out = append(out, encoded...)
+		out = append(out, d.raw[m.valEnd:]...)
+	case idx >= 0:
+		m := d.members[idx]
+		switch {
+		case idx > 0:
+			out = append(out, d.raw[:d.members[idx-1].valEnd]...)
+			out = append(out, d.raw[m.valEnd:]...)
+		case len(d.members) > 1:
+			out = append(out, d.raw[:m.keyStart]...)
+			out = append(out, d.raw[d.members[1].keyStart:]...)
+		default:
+			open := bytes.IndexByte(d.raw, '{')
+			close := bytes.LastIndexByte(d.raw, '}')
+			out = append(out, d.raw[:open+1]...)
+			out = append(out, d.raw[close:]...)
+		}
+	case keep:
+		last := d.members[len(d.members)-1]
+		out = append(out, d.raw[:last.valEnd]...)
+		out = append(out, sep+`"hooks"`+colon...)
+		out = append(out, encoded...)
+		out = append(out, d.raw[last.valEnd:]...)
+	default:
+		return d.raw, nil
+	}
+	return out, nil
+}
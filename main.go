@@ -3,7 +3,9 @@ package main
 import (
 	"fmt"
 	"os"
+	"os/exec"
 	"strings"
+	"time"
 )
 
 func main() {
@@ -12,6 +14,11 @@ func main() {
 		os.Exit(1)
 	}
 
+	// play migrates only when no daemon takes the event, to keep hooks fast.
+	if os.Args[1] != "play" {
+		migrateLegacyPaths()
+	}
+
 	switch os.Args[1] {
 	case "setup":
 		cmdSetup()
@@ -21,6 +28,10 @@ func main() {
 		cmdInstall()
 	case "uninstall":
 		cmdUninstall()
+	case "backups":
+		cmdBackups()
+	case "restore":
+		cmdRestore()
 	case "play":
 		cmdPlay()
 	case "create":
@@ -31,6 +42,20 @@ func main() {
 		cmdDelete()
 	case "volume":
 		cmdVolume()
+	case "doctor":
+		cmdDoctor()
+	case "daemon":
+		cmdDaemon()
+	case "serve":
+		cmdServe()
+	case "profile":
+		cmdProfile()
+	case "config":
+		cmdConfig()
+	case "mute":
+		cmdMute()
+	case "unmute":
+		cmdUnmute()
 	case "help", "--help", "-h":
 		printUsage()
 	default:
@@ -47,60 +72,29 @@ Usage:
   claude-bell <command>
 
 Commands:
-  setup                  Interactive setup: pick a sound for each event
+  setup [flags]          Pick a sound for each event, with live preview or
+                         --stop, --notification, --limit, --volume, --install
   test                   Play all configured sounds
-  install                Add hooks to ~/.claude/settings.json
-  uninstall              Remove hooks from ~/.claude/settings.json
+  install [flags]        Add hooks to Claude settings (--scope, --dry-run, --yes,
+                         --path-lookup)
+  uninstall [flags]      Remove hooks from Claude settings (--scope, --dry-run, --yes)
+  backups [--scope s]    List settings backups made before each change
+  restore [id]           Restore settings from a backup (default: newest)
   play <event>           Play sound for an event (used by hooks)
   create <name> <code>   Create a custom sound from an encoded string
   list                   List all custom sounds
   delete <name>          Delete a custom sound
-  volume [value]         Show or set playback volume (0-1, 0-100, or %)
+  volume [event] [value] Show or set playback volume (0-1, 0-100, or %)
+  doctor                 Diagnose why sounds are not playing
+  daemon                 Run in the background so plays start faster
+  serve [--addr a]       Play events sent from remote hosts (default 127.0.0.1:7465)
+  mute [duration]        Silence all sounds (e.g. 'mute 1h'; no duration = until unmute)
+  unmute                 Undo mute
+  profile [cmd]          Manage profiles (list, use, create, copy, delete)
+  config [cmd]           Read or change settings (get, set, unset, edit, validate, path)
 `)
 }
 
-func cmdPlay() {
-	if len(os.Args) < 3 {
-		fmt.Fprintln(os.Stderr, "usage: claude-bell play <event>")
-		os.Exit(1)
-	}
-	event := os.Args[2]
-
-	cfg, err := loadConfig()
-	if err != nil {
-		fmt.Fprintln(os.Stderr, err)
-		os.Exit(1)
-	}
-
-	var presetName string
-	switch event {
-	case "stop":
-		presetName = cfg.Stop
-	case "notification":
-		presetName = cfg.Notification
-	case "limit":
-		presetName = cfg.Limit
-	default:
-		fmt.Fprintf(os.Stderr, "unknown event: %s\n", event)
-		os.Exit(1)
-	}
-
-	if presetName == "" {
-		return // no sound configured, exit silently
-	}
-
-	path, err := ensureSound(event, presetName)
-	if err != nil {
-		fmt.Fprintln(os.Stderr, err)
-		os.Exit(1)
-	}
-
-	if err := playSound(path, cfg.Volume); err != nil {
-		fmt.Fprintln(os.Stderr, err)
-		os.Exit(1)
-	}
-}
-
 func cmdTest() {
 	cfg, err := loadConfig()
 	if err != nil {
@@ -108,28 +102,26 @@ func cmdTest() {
 		os.Exit(1)
 	}
 
-	events := []struct {
-		name   string
-		preset string
-	}{
-		{"stop", cfg.Stop},
-		{"notification", cfg.Notification},
-		{"limit", cfg.Limit},
+	if profile, _ := activeProfile(cfg, time.Now()); profile != defaultProfile {
+		fmt.Printf("Using profile %s\n", profile)
+		cfg = cfg.withProfile(profile)
 	}
 
 	any := false
-	for _, e := range events {
-		if e.preset == "" {
+	for _, event := range EventNames {
+		preset := getConfigField(cfg, event)
+		if preset == "" {
 			continue
 		}
 		any = true
-		fmt.Printf("Playing %s: %s\n", e.name, e.preset)
-		path, err := ensureSound(e.name, e.preset)
+		vol := eventVolume(cfg, event)
+		fmt.Printf("Playing %s: %s at %s\n", event, preset, formatVolume(vol))
+		path, err := ensureSound(event, preset)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "  error: %v\n", err)
 			continue
 		}
-		if err := playSound(path, cfg.Volume); err != nil {
+		if err := playSound(path, vol); err != nil {
 			fmt.Fprintf(os.Stderr, "  playback error: %v\n", err)
 		}
 	}
@@ -149,3 +141,27 @@ func executablePath() (string, bool) {
 	}
 	return exe, false
 }
+
+// warnOutdatedBinary prints a warning when this binary is not the one PATH
+// resolves to, which usually means the hooks point at an old install.
+func warnOutdatedBinary() {
+	exe, isTemp := executablePath()
+	if isTemp {
+		return
+	}
+	current, err := exec.LookPath(pathLookupBinary)
+	if err != nil || sameFile(current, exe) {
+		return
+	}
+	fmt.Fprintf(os.Stderr, "claude-bell: warning: running %s but PATH has %s; run 'claude-bell install' to update hooks\n", exe, current)
+}
+
+// isTerminal reports whether f is connected to a terminal rather than a pipe
+// or file.
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,180 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+// configVersion is the schema version this build reads and writes. Files
+// without a "version" key are version 1.
+const configVersion = 2
+
+// configMigrations[i] upgrades a config object from version i+1 to i+2.
+// Migrations edit the decoded file in place so that keys keep their order.
+var configMigrations = []func(obj *jsonObject) error{
+	migrateEventMap,
+}
+
+// migrateEventMap moves the top-level "stop", "notification" and "limit"
+// sounds of version 1 into an "events" map, where the first of them was.
+func migrateEventMap(obj *jsonObject) error {
+	events := newJSONObject()
+	out := newJSONObject()
+	for _, key := range obj.Keys() {
+		v, _ := obj.Get(key)
+		if !isEventName(key) {
+			out.Set(key, v)
+			continue
+		}
+		if _, ok := out.Get("events"); !ok {
+			out.Set("events", events)
+		}
+		if s, ok := v.(string); ok && s == "" {
+			continue // no sound
+		}
+		events.Set(key, v)
+	}
+	*obj = *out
+	return nil
+}
+
+// configFileVersion returns the schema version of a decoded config.
+func configFileVersion(obj *jsonObject) (int, error) {
+	v, ok := obj.Get("version")
+	if !ok {
+		return 1, nil
+	}
+	n, isNum := v.(json.Number)
+	if !isNum {
+		return 0, fmt.Errorf("\"version\" must be a number")
+	}
+	version, err := strconv.Atoi(n.String())
+	if err != nil || version < 1 {
+		return 0, fmt.Errorf("invalid \"version\" %s", n)
+	}
+	return version, nil
+}
+
+// migrateConfigFile upgrades the config at path, whose contents are data, to
+// configVersion and returns the upgraded contents. The original file is kept
+// as <path>.v<N>.bak before the upgraded one is written.
+func migrateConfigFile(path string, data []byte) ([]byte, error) {
+	decoded, err := decodeOrderedJSON(data)
+	if err != nil {
+		return nil, fmt.Errorf("config %s: %w", displayPath(path), err)
+	}
+	obj, ok := decoded.(*jsonObject)
+	if !ok {
+		return nil, fmt.Errorf("config %s: expected a JSON object", displayPath(path))
+	}
+
+	version, err := configFileVersion(obj)
+	if err != nil {
+		return nil, fmt.Errorf("config %s: %w", displayPath(path), err)
+	}
+	if version > configVersion {
+		return nil, fmt.Errorf("config %s is version %d, but this claude-bell only understands up to %d; upgrade claude-bell",
+			displayPath(path), version, configVersion)
+	}
+	if version == configVersion {
+		return data, nil
+	}
+
+	for v := version; v < configVersion; v++ {
+		if err := configMigrations[v-1](obj); err != nil {
+			return nil, fmt.Errorf("migrating config %s from version %d: %w", displayPath(path), v, err)
+		}
+	}
+	obj.Delete("version")
+	migrated := newJSONObject().Set("version", json.Number(strconv.Itoa(configVersion)))
+	for _, key := range obj.Keys() {
+		v, _ := obj.Get(key)
+		migrated.Set(key, v)
+	}
+
+	out, err := encodeOrderedJSON(migrated, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	backup := fmt.Sprintf("%s.v%d.bak", path, version)
+	if _, err := os.Stat(backup); err == nil {
+		backup = fmt.Sprintf("%s.v%d.%s.bak", path, version, time.Now().Format(backupTimeLayout))
+	}
+	if err := os.WriteFile(backup, data, 0644); err != nil {
+		fmt.Fprintf(os.Stderr, "claude-bell: warning: config migration: %v\n", err)
+		return out, nil // use the upgraded config, but leave the file alone
+	}
+	if err := writeFileAtomic(path, out); err != nil {
+		fmt.Fprintf(os.Stderr, "claude-bell: warning: config migration: %v\n", err)
+	} else {
+		fmt.Fprintf(os.Stderr, "claude-bell: upgraded config %s to version %d (previous version saved as %s)\n",
+			displayPath(path), configVersion, displayPath(backup))
+	}
+	return out, nil
+}
+
+// migrateLegacyPaths brings over files from where older versions kept them.
+// The config and custom sounds are copied out of ~/.config/claude-bell when
+// XDG_CONFIG_HOME points elsewhere, and rendered sounds, which used to be
+// cached there too, are deleted since they are regenerated on demand.
+func migrateLegacyPaths() {
+	old := legacyConfigDir()
+	dir := configDir()
+	if old == "" || dir == "" {
+		return
+	}
+
+	// CLAUDE_BELL_CONFIG is often a temporary alternative, so only a moved
+	// XDG_CONFIG_HOME takes the config along. The originals stay, since hooks
+	// and other processes started without XDG_CONFIG_HOME still read them.
+	if os.Getenv("CLAUDE_BELL_CONFIG") == "" && !samePath(old, dir) {
+		for _, name := range []string{"config.json", "custom-sounds.json"} {
+			from, to := filepath.Join(old, name), filepath.Join(dir, name)
+			if _, err := os.Stat(to); err == nil {
+				continue
+			}
+			if _, err := os.Stat(from); err != nil {
+				continue
+			}
+			if err := copyFile(from, to); err != nil {
+				fmt.Fprintf(os.Stderr, "claude-bell: warning: copying %s: %v\n", displayPath(from), err)
+				continue
+			}
+			fmt.Fprintf(os.Stderr, "claude-bell: copied %s to %s; processes without XDG_CONFIG_HOME, such as hooks started from a desktop session, still use the original\n",
+				displayPath(from), displayPath(to))
+		}
+	}
+
+	oldSounds := filepath.Join(old, "sounds")
+	if samePath(oldSounds, soundsDir()) {
+		return
+	}
+	wavs, _ := filepath.Glob(filepath.Join(oldSounds, "*.wav"))
+	for _, wav := range wavs {
+		os.Remove(wav)
+	}
+	if len(wavs) > 0 {
+		os.Remove(oldSounds) // only if nothing else is in it
+	}
+}
+
+func samePath(a, b string) bool {
+	return filepath.Clean(a) == filepath.Clean(b) || sameFile(a, b)
+}
+
+// copyFile copies from to to, creating the destination directory.
+func copyFile(from, to string) error {
+	if err := os.MkdirAll(filepath.Dir(to), 0755); err != nil {
+		return err
+	}
+	data, err := os.ReadFile(from)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(to, data)
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"fmt"
+	"os/exec"
+	"runtime"
+)
+
+// NotifyConfig enables a desktop notification for an event. Title and Body
+// are templates over eventContext.
+type NotifyConfig struct {
+	Title   string `json:"title,omitempty"`
+	Body    string `json:"body,omitempty"`
+	Urgency string `json:"urgency,omitempty"` // low, normal, or critical
+}
+
+const (
+	defaultNotifyTitle = "Claude Code · {{.Project}}"
+	defaultNotifyBody  = "{{.Message}} in {{.Dir}}"
+)
+
+var notifyUrgencies = map[string]byte{
+	"low":      0,
+	"normal":   1,
+	"critical": 2,
+}
+
+// sendEventNotification shows the desktop notification configured for an
+// event, or one with the default text when nc is nil.
+func sendEventNotification(nc *NotifyConfig, ctx eventContext) error {
+	n := NotifyConfig{}
+	if nc != nil {
+		n = *nc
+	}
+	if n.Title == "" {
+		n.Title = defaultNotifyTitle
+	}
+	if n.Body == "" {
+		n.Body = defaultNotifyBody
+	}
+	return sendDesktopNotification(ctx.expand(n.Title), ctx.expand(n.Body), n.Urgency)
+}
+
+// sendDesktopNotification shows a notification through the freedesktop
+// notification service on D-Bus, or Notification Center on macOS.
+func sendDesktopNotification(title, body, urgency string) error {
+	if runtime.GOOS == "darwin" {
+		script := fmt.Sprintf("display notification %q with title %q", body, title)
+		return exec.Command("osascript", "-e", script).Run()
+	}
+
+	level, ok := notifyUrgencies[urgency]
+	if !ok && urgency != "" {
+		return fmt.Errorf("unknown urgency %q (use low, normal, or critical)", urgency)
+	}
+	if urgency == "" {
+		level = notifyUrgencies["normal"]
+	}
+
+	conn, err := dbusSessionConn()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	// Notify(app_name s, replaces_id u, app_icon s, summary s, body s,
+	//        actions as, hints a{sv}, expire_timeout i)
+	var e dbusEncoder
+	e.string("claude-bell")
+	e.uint32(0)
+	e.string("")
+	e.string(title)
+	e.string(body)
+	e.uint32(0) // no actions
+
+	hintsLen := e.arrayStart()
+	e.align(8)
+	hintsStart := len(e.buf)
+	e.align(8)
+	e.string("urgency")
+	e.signature("y")
+	e.byte(level)
+	e.arrayEnd(hintsLen, hintsStart)
+
+	e.int32(-1) // server default timeout
+
+	_, err = conn.call("org.freedesktop.Notifications", "/org/freedesktop/Notifications",
+		"org.freedesktop.Notifications", "Notify", "susssasa{sv}i", e.buf)
+	return err
+}
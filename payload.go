@@ -0,0 +1,105 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+)
+
+const payloadReadTimeout = 500 * time.Millisecond
+
+// hookPayload is the JSON Claude Code writes to a hook's stdin. Only the
+// fields claude-bell uses are decoded.
+type hookPayload struct {
+	SessionID     string `json:"session_id,omitempty"`
+	Cwd           string `json:"cwd,omitempty"`
+	HookEventName string `json:"hook_event_name,omitempty"`
+	Message       string `json:"message,omitempty"`
+	Title         string `json:"title,omitempty"`
+	Trigger       string `json:"trigger,omitempty"`
+}
+
+// readHookPayload decodes the hook payload from stdin. It returns an empty
+// payload when stdin is a terminal, empty, not JSON, or not closed promptly.
+func readHookPayload() hookPayload {
+	var p hookPayload
+	if isTerminal(os.Stdin) {
+		return p
+	}
+
+	done := make(chan []byte, 1)
+	go func() {
+		data, _ := io.ReadAll(io.LimitReader(os.Stdin, 1<<20))
+		done <- data
+	}()
+
+	select {
+	case data := <-done:
+		json.Unmarshal(bytes.TrimSpace(data), &p)
+	case <-time.After(payloadReadTimeout):
+	}
+	return p
+}
+
+// eventContext holds the values available to notification, webhook and
+// speech templates, e.g. "{{.Project}}: {{.Message}}".
+type eventContext struct {
+	Event       string // stop, notification, or limit
+	Description string // human-readable description of the event
+	Preset      string // configured sound, if any
+	Message     string // the hook's message, or Description when there is none
+	Project     string // base name of the session's working directory
+	Dir         string // working directory, shortened to ~/...
+	Cwd         string // working directory as given by Claude Code
+	Host        string
+	SessionID   string
+}
+
+func newEventContext(req playRequest, preset string) eventContext {
+	ctx := eventContext{
+		Event:       req.Event,
+		Description: EventDescriptions[req.Event],
+		Preset:      preset,
+		Message:     req.Payload.Message,
+		Cwd:         req.Payload.Cwd,
+		SessionID:   req.Payload.SessionID,
+	}
+	if ctx.Message == "" {
+		ctx.Message = ctx.Description
+	}
+	if ctx.Cwd != "" {
+		ctx.Project = filepath.Base(ctx.Cwd)
+		ctx.Dir = displayPath(ctx.Cwd)
+	}
+	ctx.Host = req.Host
+	if ctx.Host == "" {
+		ctx.Host, _ = os.Hostname()
+	}
+	return ctx
+}
+
+// templateFuncs are available to every template; env reads an environment
+// variable, e.g. for a token in a webhook header.
+var templateFuncs = template.FuncMap{"env": os.Getenv}
+
+// expand renders a template against the context. A template that fails to
+// parse or execute is returned as-is so a typo still yields readable text.
+func (c eventContext) expand(text string) string {
+	if !strings.Contains(text, "{{") {
+		return text
+	}
+	tmpl, err := template.New("").Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return text
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, c); err != nil {
+		return text
+	}
+	return buf.String()
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"sync"
+	"time"
+)
+
+// playRequest is one event to play, either handled directly by
+// 'claude-bell play' or forwarded to the daemon.
+type playRequest struct {
+	Event   string      `json:"event"`
+	Payload hookPayload `json:"payload"`
+	TTY     string      `json:"tty,omitempty"`  // session terminal, when known
+	Host    string      `json:"host,omitempty"` // sending machine, when forwarded to a remote
+}
+
+// pendingChannels tracks channels sent in the background, such as webhooks,
+// so 'claude-bell play' can wait for them after the sound has started.
+var pendingChannels sync.WaitGroup
+
+// soundFunc plays the sound named preset for an event, then speaks phrase.
+// Either may be empty.
+type soundFunc func(cfg Config, event, preset, phrase string, volume float64) error
+
+func cmdPlay() {
+	if len(os.Args) < 3 {
+		fmt.Fprintln(os.Stderr, "usage: claude-bell play <event>")
+		os.Exit(1)
+	}
+	req := playRequest{Event: os.Args[2], Payload: readHookPayload()}
+	if req.Payload.Cwd == "" {
+		req.Payload.Cwd, _ = os.Getwd()
+	}
+
+	warnOutdatedBinary()
+
+	// A running daemon has the config loaded already, including any remote
+	// to forward to, so hand the event over before reading anything.
+	if handled, err := forwardToDaemon(req); handled {
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	migrateLegacyPaths()
+	cfg, err := loadPlayConfig()
+	if err == nil && cfg.Remote != nil && cfg.Remote.URL != "" {
+		if err := forwardToRemote(cfg.Remote, req); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	err = playEvent(cfg, req, playDirect)
+	pendingChannels.Wait()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// playEvent decides whether and how an event should sound and which other
+// channels it triggers, then hands the chosen preset to play. It is shared by
+// direct playback and the daemon.
+func playEvent(cfg Config, req playRequest, play soundFunc) error {
+	if !isEventName(req.Event) {
+		return fmt.Errorf("unknown event: %s", req.Event)
+	}
+	now := time.Now()
+	profile, _ := activeProfile(cfg, now)
+	cfg = cfg.withProfile(profile)
+	presetName := getConfigField(cfg, req.Event)
+
+	notifyCfg, notify := cfg.Notify[req.Event]
+	webhook := cfg.Webhook.wantsEvent(req.Event)
+	speakCfg, speak := cfg.Speak[req.Event]
+	terminalCfg, terminal := cfg.Terminal[req.Event]
+	if presetName == "" && !notify && !webhook && !speak && !terminal {
+		return nil // nothing configured, exit silently
+	}
+
+	// Mute and quiet hours silence everything here, but webhooks reach
+	// people away from the desk, so they still go out.
+	volume, audible := quietVolume(cfg, req.Event, eventVolume(cfg, req.Event), now)
+	if !audible && !webhook {
+		return nil // muted or quiet hours
+	}
+
+	allowed, err := claimPlaySlot(cfg, req.Event, now)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "claude-bell: warning: rate limit state: %v\n", err)
+	} else if !allowed {
+		return nil // within a cooldown, collapse into the event that just fired
+	}
+
+	ctx := newEventContext(req, presetName)
+	if webhook {
+		pendingChannels.Add(1)
+		go func() {
+			defer pendingChannels.Done()
+			if err := sendWebhook(cfg.Webhook, ctx, req.Payload); err != nil {
+				fmt.Fprintf(os.Stderr, "claude-bell: warning: webhook: %v\n", err)
+			}
+		}()
+	}
+	if !audible {
+		return nil
+	}
+	if notify {
+		if err := sendEventNotification(notifyCfg, ctx); err != nil {
+			fmt.Fprintf(os.Stderr, "claude-bell: warning: desktop notification: %v\n", err)
+		}
+	}
+
+	var phrase string
+	if speak {
+		phrase = speakPhrase(speakCfg, ctx)
+	}
+	sound := presetName != "" || phrase != ""
+	if !sound && !terminal {
+		return nil
+	}
+
+	// Quiet hours that lower the chime also hold back the terminal bell,
+	// which always beeps at full volume, as does a busy policy that stops
+	// the chime.
+	hushed := volume < eventVolume(cfg, req.Event)
+	volume, ok, visual := applyBusyPolicy(cfg, volume)
+	if terminal {
+		if err := sendTerminalAlert(terminalCfg, ctx, req.TTY, hushed || !ok); err != nil {
+			fmt.Fprintf(os.Stderr, "claude-bell: warning: terminal alert: %v\n", err)
+		}
+	}
+	if !sound {
+		return nil
+	}
+	if visual && !notify {
+		if err := sendEventNotification(nil, ctx); err != nil {
+			fmt.Fprintf(os.Stderr, "claude-bell: warning: desktop notification: %v\n", err)
+		}
+	}
+	if !ok {
+		return nil // on a call or other audio playing
+	}
+
+	return play(cfg, req.Event, presetName, phrase, volume)
+}
+
+// playDirect renders the sound to the cache if needed and plays it.
+func playDirect(cfg Config, event, preset, phrase string, volume float64) error {
+	var path string
+	if preset != "" {
+		var err error
+		if path, err = ensureSound(event, preset); err != nil {
+			return err
+		}
+	}
+	return playSerialized(cfg, event, playSteps(cfg, event, path, phrase, volume)...)
+}
+
+// playSteps returns the steps that play a cached sound file and then speak
+// phrase, skipping whichever is empty.
+func playSteps(cfg Config, event, path, phrase string, volume float64) []playStep {
+	var steps []playStep
+	if path != "" {
+		steps = append(steps, func() (*exec.Cmd, error) { return startSound(path, volume) })
+	}
+	if phrase != "" {
+		sc := cfg.Speak[event]
+		steps = append(steps, func() (*exec.Cmd, error) { return startSpeech(sc, phrase, volume) })
+	}
+	return steps
+}
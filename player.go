@@ -6,16 +6,26 @@ import (
 	"os/exec"
 	"path/filepath"
 	"strconv"
+	"strings"
 )
 
+// renderVersion is bumped whenever renderTones changes its output, so WAV
+// files cached by older versions are not reused.
+const renderVersion = 3
+
+// soundPath returns where the rendered WAV for an event's preset is cached.
+func soundPath(event, presetName string) string {
+	filename := fmt.Sprintf("%s_%s_v%d.wav", event, sanitize(presetName), renderVersion)
+	return filepath.Join(soundsDir(), filename)
+}
+
 func ensureSound(event, presetName string) (string, error) {
 	dir := soundsDir()
 	if err := os.MkdirAll(dir, 0755); err != nil {
 		return "", err
 	}
 
-	filename := fmt.Sprintf("%s_%s.wav", event, sanitize(presetName))
-	path := filepath.Join(dir, filename)
+	path := soundPath(event, presetName)
 
 	if _, err := os.Stat(path); err == nil {
 		return path, nil
@@ -48,12 +58,81 @@ func ensureSound(event, presetName string) (string, error) {
 	return "", fmt.Errorf("unknown preset %q for event %q", presetName, event)
 }
 
-// playSound plays a WAV file using afplay. It blocks until playback finishes.
+// lookupTones resolves a sound name to its tones, checking the event's
+// built-in presets before custom sounds.
+func lookupTones(event, name string, customSounds []CustomSound) ([]Tone, bool) {
+	for _, p := range EventPresets[event] {
+		if p.Name == name {
+			return p.Tones, true
+		}
+	}
+	for _, cs := range customSounds {
+		if strings.EqualFold(cs.Name, name) {
+			return cs.Tones, true
+		}
+	}
+	return nil, false
+}
+
+// audioBackend is a command-line WAV player claude-bell can drive.
+type audioBackend struct {
+	name string
+	args func(path string, volume float64) []string
+}
+
+// audioBackends lists supported players in order of preference.
+var audioBackends = []audioBackend{
+	{
+		name: "afplay", // macOS
+		args: func(path string, volume float64) []string {
+			return []string{"-v", strconv.FormatFloat(volume, 'f', 2, 64), path}
+		},
+	},
+	{
+		name: "pw-play", // PipeWire
+		args: func(path string, volume float64) []string {
+			return []string{"--volume", strconv.FormatFloat(volume, 'f', 2, 64), path}
+		},
+	},
+	{
+		name: "paplay", // PulseAudio
+		args: func(path string, volume float64) []string {
+			return []string{"--volume", strconv.Itoa(int(volume * 65536)), path}
+		},
+	},
+}
+
+// findAudioBackend returns the first available player and its resolved path.
+func findAudioBackend() (audioBackend, string, error) {
+	for _, b := range audioBackends {
+		if path, err := exec.LookPath(b.name); err == nil {
+			return b, path, nil
+		}
+	}
+	names := make([]string, len(audioBackends))
+	for i, b := range audioBackends {
+		names[i] = b.name
+	}
+	return audioBackend{}, "", fmt.Errorf("no audio player found (need one of: %s)", strings.Join(names, ", "))
+}
+
+// playSound plays a WAV file with the first available audio backend. It
+// blocks until playback finishes.
 func playSound(path string, volume float64) error {
-	vol := clampVolume(volume)
-	cmd := exec.Command("afplay", "-v", strconv.FormatFloat(vol, 'f', 2, 64), path)
-	cmd.Stderr = os.Stderr
-	return cmd.Run()
+	cmd, err := startSound(path, volume)
+	if err != nil {
+		return err
+	}
+	return cmd.Wait()
+}
+
+// startSound starts playing a WAV file and returns the running player.
+func startSound(path string, volume float64) (*exec.Cmd, error) {
+	backend, bin, err := findAudioBackend()
+	if err != nil {
+		return nil, err
+	}
+	return startCommand(exec.Command(bin, backend.args(path, clampVolume(volume))...))
 }
 
 // sanitize replaces spaces with underscores and lowercases for filenames.
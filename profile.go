@@ -0,0 +1,340 @@
+package main
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"os/exec"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Profile is a named set of sounds, volumes and channels that replaces the
+// top-level ones while it is active.
+type Profile struct {
+	Events   map[string]string          `json:"events,omitempty"`
+	Volume   *float64                   `json:"volume,omitempty"` // default 1
+	Volumes  map[string]float64         `json:"volumes,omitempty"`
+	Notify   map[string]*NotifyConfig   `json:"notify,omitempty"`
+	Webhook  *WebhookConfig             `json:"webhook,omitempty"`
+	Speak    map[string]*SpeakConfig    `json:"speak,omitempty"`
+	Terminal map[string]*TerminalConfig `json:"terminal,omitempty"`
+}
+
+// ProfileRule selects a profile automatically. A rule matches when every
+// condition it sets holds: the connected Wi-Fi network and the time window
+// (days defaulting to every day). A rule with no conditions always matches.
+type ProfileRule struct {
+	Profile string `json:"profile"`
+	WiFi    string `json:"wifi,omitempty"`
+	QuietWindow
+}
+
+// defaultProfile names the top-level settings; autoProfile, as the active
+// profile, lets the rules choose.
+const (
+	defaultProfile = "default"
+	autoProfile    = "auto"
+)
+
+// activeProfile returns the profile to use at now: the one chosen with
+// 'claude-bell profile use', else the first matching rule, else the default.
+// auto reports whether a rule chose it.
+func activeProfile(cfg Config, now time.Time) (name string, auto bool) {
+	if cfg.Profile != "" && cfg.Profile != autoProfile {
+		return cfg.Profile, false
+	}
+
+	var ssid *string
+	currentSSID := func() string {
+		if ssid == nil {
+			s := wifiSSID()
+			ssid = &s
+		}
+		return *ssid
+	}
+	for _, rule := range cfg.ProfileRules {
+		ok, err := rule.matches(now, currentSSID)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "claude-bell: warning: profile rule for %q: %v\n", rule.Profile, err)
+			continue
+		}
+		if ok {
+			return rule.Profile, true
+		}
+	}
+	return defaultProfile, false
+}
+
+func (r ProfileRule) matches(now time.Time, ssid func() string) (bool, error) {
+	if r.WiFi != "" && !strings.EqualFold(ssid(), r.WiFi) {
+		return false, nil
+	}
+	if len(r.Days) == 0 && r.Start == "" && r.End == "" {
+		return true, nil
+	}
+	w := r.QuietWindow
+	if len(w.Days) == 0 {
+		w.Days = []string{"daily"}
+	}
+	return QuietConfig{Schedules: []QuietWindow{w}}.active(now)
+}
+
+// withProfile returns cfg with the named profile's settings in place of the
+// top-level ones. The default or an unknown profile leaves cfg unchanged.
+func (cfg Config) withProfile(name string) Config {
+	p, ok := cfg.Profiles[name]
+	if !ok || p == nil {
+		return cfg
+	}
+	cfg.Events = p.Events
+	cfg.Volume = 1.0
+	if p.Volume != nil {
+		cfg.Volume = *p.Volume
+	}
+	cfg.Volumes = p.Volumes
+	cfg.Notify = p.Notify
+	cfg.Webhook = p.Webhook
+	cfg.Speak = p.Speak
+	cfg.Terminal = p.Terminal
+	return cfg
+}
+
+// hookedEvents returns the events, in EventNames order, that the top-level
+// settings or any profile do something for. Hooks are installed for all of
+// them, since any profile can become active without reinstalling.
+func hookedEvents(cfg Config) []string {
+	var events []string
+	for _, event := range EventNames {
+		if eventConfigured(cfg, event) {
+			events = append(events, event)
+			continue
+		}
+		for _, name := range sortedKeys(cfg.Profiles) {
+			if eventConfigured(cfg.withProfile(name), event) {
+				events = append(events, event)
+				break
+			}
+		}
+	}
+	return events
+}
+
+// eventConfigured reports whether cfg plays, shows or sends anything for
+// event.
+func eventConfigured(cfg Config, event string) bool {
+	_, notify := cfg.Notify[event]
+	_, speak := cfg.Speak[event]
+	_, terminal := cfg.Terminal[event]
+	return getConfigField(cfg, event) != "" || notify || speak || terminal || cfg.Webhook.wantsEvent(event)
+}
+
+// activeSettings returns the name of the profile in effect and cfg with its
+// settings in place, for commands that show or change them.
+func activeSettings(cfg Config) (string, Config) {
+	name, _ := activeProfile(cfg, time.Now())
+	return name, cfg.withProfile(name)
+}
+
+// storeProfileSettings saves settings, which started as
+// cfg.withProfile(name), back into the named profile. For the default
+// profile settings already is the whole config.
+func storeProfileSettings(cfg Config, name string, settings Config) Config {
+	if cfg.Profiles[name] == nil {
+		return settings
+	}
+	profiles := maps.Clone(cfg.Profiles)
+	profiles[name] = profileOf(settings)
+	cfg.Profiles = profiles
+	return cfg
+}
+
+// profileOf captures the profile-level settings of cfg.
+func profileOf(cfg Config) *Profile {
+	volume := cfg.Volume
+	return &Profile{
+		Events:   maps.Clone(cfg.Events),
+		Volume:   &volume,
+		Volumes:  maps.Clone(cfg.Volumes),
+		Notify:   maps.Clone(cfg.Notify),
+		Webhook:  cfg.Webhook,
+		Speak:    maps.Clone(cfg.Speak),
+		Terminal: maps.Clone(cfg.Terminal),
+	}
+}
+
+// wifiSSID returns the name of the connected Wi-Fi network, or "" if it
+// cannot be determined.
+func wifiSSID() string {
+	if runtime.GOOS == "darwin" {
+		out, err := exec.Command("networksetup", "-getairportnetwork", "en0").Output()
+		if err != nil {
+			return ""
+		}
+		_, ssid, ok := strings.Cut(strings.TrimSpace(string(out)), "Current Wi-Fi Network: ")
+		if !ok {
+			return ""
+		}
+		return ssid
+	}
+
+	// --rescan no keeps nmcli from blocking on a fresh scan.
+	if out, err := exec.Command("nmcli", "-t", "-f", "active,ssid", "dev", "wifi", "list", "--rescan", "no").Output(); err == nil {
+		for _, line := range strings.Split(string(out), "\n") {
+			if ssid, ok := strings.CutPrefix(line, "yes:"); ok {
+				return strings.ReplaceAll(ssid, `\:`, ":")
+			}
+		}
+	}
+	if out, err := exec.Command("iwgetid", "-r").Output(); err == nil {
+		return strings.TrimSpace(string(out))
+	}
+	return ""
+}
+
+func cmdProfile() {
+	cfg, err := loadConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error loading config: %v\n", err)
+		os.Exit(1)
+	}
+
+	args := os.Args[2:]
+	if len(args) == 0 {
+		args = []string{"list"}
+	}
+
+	switch args[0] {
+	case "list":
+		listProfiles(cfg)
+		return
+	case "use":
+		if len(args) != 2 {
+			profileUsage()
+		}
+		name := args[1]
+		if name != defaultProfile && name != autoProfile && cfg.Profiles[name] == nil {
+			fmt.Fprintf(os.Stderr, "error: no profile named %q\n", name)
+			os.Exit(1)
+		}
+		cfg.Profile = name
+		if name == autoProfile {
+			cfg.Profile = ""
+		}
+		saveProfileConfig(cfg)
+		if name == autoProfile {
+			active, _ := activeProfile(cfg, time.Now())
+			fmt.Printf("Profile rules now choose the profile (currently %s)\n", active)
+		} else {
+			fmt.Printf("Using profile %s\n", name)
+		}
+	case "create", "copy":
+		var src, dst string
+		switch {
+		case args[0] == "create" && len(args) == 2:
+			src, dst = defaultProfile, args[1]
+		case args[0] == "copy" && len(args) == 3:
+			src, dst = args[1], args[2]
+		default:
+			profileUsage()
+		}
+		if err := validProfileName(dst); err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(1)
+		}
+		if _, exists := cfg.Profiles[dst]; exists {
+			fmt.Fprintf(os.Stderr, "error: profile %q already exists\n", dst)
+			os.Exit(1)
+		}
+		if src != defaultProfile && cfg.Profiles[src] == nil {
+			fmt.Fprintf(os.Stderr, "error: no profile named %q\n", src)
+			os.Exit(1)
+		}
+		if cfg.Profiles == nil {
+			cfg.Profiles = make(map[string]*Profile)
+		}
+		cfg.Profiles[dst] = profileOf(cfg.withProfile(src))
+		saveProfileConfig(cfg)
+		fmt.Printf("Created profile %s from %s\n", dst, src)
+		fmt.Printf("Edit it under \"profiles\" in %s, then run: claude-bell profile use %s\n", displayPath(configPath()), dst)
+	case "delete":
+		if len(args) != 2 {
+			profileUsage()
+		}
+		name := args[1]
+		if cfg.Profiles[name] == nil {
+			fmt.Fprintf(os.Stderr, "error: no profile named %q\n", name)
+			os.Exit(1)
+		}
+		for _, rule := range cfg.ProfileRules {
+			if rule.Profile == name {
+				fmt.Fprintf(os.Stderr, "error: profile %q is used by a rule in \"profile_rules\"; remove the rule first\n", name)
+				os.Exit(1)
+			}
+		}
+		delete(cfg.Profiles, name)
+		if cfg.Profile == name {
+			cfg.Profile = ""
+		}
+		saveProfileConfig(cfg)
+		fmt.Printf("Deleted profile %s\n", name)
+	default:
+		profileUsage()
+	}
+}
+
+func listProfiles(cfg Config) {
+	active, auto := activeProfile(cfg, time.Now())
+	names := append([]string{defaultProfile}, mapKeys(cfg.Profiles)...)
+	sort.Strings(names[1:])
+
+	for _, name := range names {
+		marker := "  "
+		if name == active {
+			marker = "* "
+		}
+		var events []string
+		for _, e := range EventNames {
+			if preset := getConfigField(cfg.withProfile(name), e); preset != "" {
+				events = append(events, e+"="+preset)
+			}
+		}
+		summary := strings.Join(events, ", ")
+		if summary == "" {
+			summary = "silent"
+		}
+		fmt.Printf("%s%-14s %s\n", marker, name, summary)
+	}
+
+	switch {
+	case auto:
+		fmt.Printf("\n%s was chosen by a profile rule.\n", active)
+	case cfg.Profile == "" && len(cfg.ProfileRules) > 0:
+		fmt.Println("\nNo profile rule matches right now.")
+	}
+}
+
+func validProfileName(name string) error {
+	if name == "" || name == defaultProfile || name == autoProfile || strings.ContainsAny(name, " \t\n") {
+		return fmt.Errorf("invalid profile name %q", name)
+	}
+	return nil
+}
+
+func saveProfileConfig(cfg Config) {
+	if err := saveConfig(cfg); err != nil {
+		fmt.Fprintf(os.Stderr, "error saving config: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func profileUsage() {
+	fmt.Fprintln(os.Stderr, "usage: claude-bell profile list")
+	fmt.Fprintln(os.Stderr, "       claude-bell profile use <name|default|auto>")
+	fmt.Fprintln(os.Stderr, "       claude-bell profile create <name>")
+	fmt.Fprintln(os.Stderr, "       claude-bell profile copy <from> <to>")
+	fmt.Fprintln(os.Stderr, "       claude-bell profile delete <name>")
+	os.Exit(1)
+}
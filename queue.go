@@ -0,0 +1,126 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"syscall"
+)
+
+// Playback policies for when another claude-bell process is already playing.
+const (
+	policyQueue   = "queue"
+	policyDrop    = "drop"
+	policyPreempt = "preempt"
+)
+
+// nowPlaying describes the sound held by the process owning the playback lock.
+type nowPlaying struct {
+	PID      int    `json:"pid"` // player process
+	Event    string `json:"event"`
+	Priority int    `json:"priority"`
+}
+
+func playbackLockPath() string {
+	return filepath.Join(configDir(), "playback.lock")
+}
+
+func nowPlayingPath() string {
+	return filepath.Join(configDir(), "playing.json")
+}
+
+// playStep starts one part of an event's playback, such as its sound or a
+// spoken phrase.
+type playStep func() (*exec.Cmd, error)
+
+// playSerialized runs the steps for event in order while holding the
+// cross-process playback lock, so sounds from concurrent hooks never overlap.
+// What happens when the lock is busy depends on cfg.PlaybackPolicy.
+func playSerialized(cfg Config, event string, steps ...playStep) error {
+	lock, ok, err := tryLockFile(playbackLockPath())
+	if err != nil {
+		// Without a lock we can still play, just not serialized.
+		return runSteps(steps, nil)
+	}
+
+	if !ok {
+		switch cfg.PlaybackPolicy {
+		case policyDrop:
+			return nil
+		case policyPreempt:
+			preemptLowerPriority(eventPriority(cfg, event))
+		case "", policyQueue:
+		default:
+			return fmt.Errorf("unknown playback_policy %q (use queue, drop, or preempt)", cfg.PlaybackPolicy)
+		}
+		lock, err = lockFile(playbackLockPath())
+		if err != nil {
+			return runSteps(steps, nil)
+		}
+	}
+	defer unlockFile(lock)
+	defer os.Remove(nowPlayingPath())
+
+	return runSteps(steps, func(cmd *exec.Cmd) {
+		writeNowPlaying(nowPlaying{PID: cmd.Process.Pid, Event: event, Priority: eventPriority(cfg, event)})
+	})
+}
+
+// runSteps starts each step after the previous one finishes, calling started
+// (if set) with each running process. A preempted step ends the sequence.
+func runSteps(steps []playStep, started func(*exec.Cmd)) error {
+	for _, step := range steps {
+		cmd, err := step()
+		if err != nil {
+			return err
+		}
+		if started != nil {
+			started(cmd)
+		}
+		if err := cmd.Wait(); err != nil {
+			if wasPreempted(err) {
+				return nil
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// preemptLowerPriority stops the current player if its event ranks below
+// priority.
+func preemptLowerPriority(priority int) {
+	data, err := os.ReadFile(nowPlayingPath())
+	if err != nil {
+		return
+	}
+	var cur nowPlaying
+	if err := json.Unmarshal(data, &cur); err != nil || cur.PID <= 0 {
+		return
+	}
+	if cur.Priority < priority {
+		syscall.Kill(cur.PID, syscall.SIGTERM)
+	}
+}
+
+func writeNowPlaying(np nowPlaying) {
+	data, err := json.Marshal(np)
+	if err != nil {
+		return
+	}
+	writeFileAtomic(nowPlayingPath(), data)
+}
+
+// wasPreempted reports whether a player exited because another claude-bell
+// process sent it SIGTERM.
+func wasPreempted(err error) bool {
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		return false
+	}
+	status, ok := exitErr.Sys().(syscall.WaitStatus)
+	return ok && status.Signaled() && status.Signal() == syscall.SIGTERM
+}
@@ -0,0 +1,209 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// QuietConfig silences or softens sounds on a weekly schedule.
+type QuietConfig struct {
+	Schedules []QuietWindow `json:"schedules,omitempty"`
+	// Volumes lists events that still sound during quiet hours and the
+	// volume they use. Events not listed are dropped.
+	Volumes map[string]float64 `json:"volumes,omitempty"`
+}
+
+// QuietWindow is a daily time range on the given days. An empty start and
+// end mean the whole day; an end before the start runs past midnight into
+// the next day.
+type QuietWindow struct {
+	Days  []string `json:"days"`            // mon..sun, "weekdays", "weekend", or "daily"
+	Start string   `json:"start,omitempty"` // HH:MM
+	End   string   `json:"end,omitempty"`   // HH:MM
+}
+
+var weekdayNames = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+// quietVolume applies mute and quiet hours to an event. It returns the
+// volume to play at, or ok=false if the event should be silent.
+func quietVolume(cfg Config, event string, volume float64, now time.Time) (float64, bool) {
+	if st, err := readState(); err == nil && st.mutedAt(now) {
+		return 0, false
+	}
+	if cfg.Quiet == nil {
+		return volume, true
+	}
+
+	quiet, err := cfg.Quiet.active(now)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "claude-bell: warning: ignoring quiet hours: %v\n", err)
+		return volume, true
+	}
+	if !quiet {
+		return volume, true
+	}
+	v, ok := cfg.Quiet.Volumes[event]
+	if !ok {
+		return 0, false
+	}
+	return min(clampVolume(v), volume), true
+}
+
+// active reports whether now falls inside any quiet window.
+func (q QuietConfig) active(now time.Time) (bool, error) {
+	minute := now.Hour()*60 + now.Minute()
+	today := now.Weekday()
+	yesterday := (today + 6) % 7
+
+	for _, w := range q.Schedules {
+		days, err := w.weekdays()
+		if err != nil {
+			return false, err
+		}
+		start, end, err := w.minutes()
+		if err != nil {
+			return false, err
+		}
+
+		switch {
+		case start == end:
+			if days[today] {
+				return true, nil
+			}
+		case start < end:
+			if days[today] && minute >= start && minute < end {
+				return true, nil
+			}
+		default: // overnight
+			if days[today] && minute >= start {
+				return true, nil
+			}
+			if days[yesterday] && minute < end {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+func (w QuietWindow) weekdays() (map[time.Weekday]bool, error) {
+	days := make(map[time.Weekday]bool)
+	for _, d := range w.Days {
+		switch name := strings.ToLower(strings.TrimSpace(d)); name {
+		case "daily", "all":
+			for _, wd := range weekdayNames {
+				days[wd] = true
+			}
+		case "weekdays":
+			for wd := time.Monday; wd <= time.Friday; wd++ {
+				days[wd] = true
+			}
+		case "weekend", "weekends":
+			days[time.Saturday] = true
+			days[time.Sunday] = true
+		default:
+			wd, ok := weekdayNames[name[:min(len(name), 3)]]
+			if !ok {
+				return nil, fmt.Errorf("unknown day %q", d)
+			}
+			days[wd] = true
+		}
+	}
+	if len(days) == 0 {
+		return nil, fmt.Errorf("quiet window has no days")
+	}
+	return days, nil
+}
+
+func (w QuietWindow) minutes() (start, end int, err error) {
+	if start, err = parseClock(w.Start); err != nil {
+		return 0, 0, err
+	}
+	if end, err = parseClock(w.End); err != nil {
+		return 0, 0, err
+	}
+	return start, end, nil
+}
+
+// parseClock parses HH:MM into minutes after midnight. Empty means 00:00.
+func parseClock(s string) (int, error) {
+	if s == "" {
+		return 0, nil
+	}
+	h, m, ok := strings.Cut(s, ":")
+	hour, err1 := strconv.Atoi(h)
+	minute, err2 := strconv.Atoi(m)
+	if !ok || err1 != nil || err2 != nil || hour < 0 || hour > 24 || minute < 0 || minute > 59 || (hour == 24 && minute != 0) {
+		return 0, fmt.Errorf("invalid time %q (use HH:MM)", s)
+	}
+	return hour*60 + minute, nil
+}
+
+// mutedAt reports whether an ad-hoc mute is in effect at now.
+func (st playState) mutedAt(now time.Time) bool {
+	return st.Muted && (st.MutedUntil.IsZero() || now.Before(st.MutedUntil))
+}
+
+func cmdMute() {
+	if len(os.Args) > 3 {
+		fmt.Fprintln(os.Stderr, "usage: claude-bell mute [duration]")
+		os.Exit(1)
+	}
+
+	var until time.Time
+	if len(os.Args) == 3 {
+		d, err := time.ParseDuration(os.Args[2])
+		if err != nil || d <= 0 {
+			fmt.Fprintf(os.Stderr, "error: invalid duration %q (e.g. 30m, 2h, 1h30m)\n", os.Args[2])
+			os.Exit(1)
+		}
+		until = time.Now().Add(d)
+	}
+
+	err := updateState(func(st *playState) bool {
+		st.Muted = true
+		st.MutedUntil = until
+		return true
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if until.IsZero() {
+		fmt.Println("Muted until 'claude-bell unmute'.")
+	} else {
+		fmt.Printf("Muted until %s.\n", until.Format("Mon 15:04"))
+	}
+}
+
+func cmdUnmute() {
+	wasMuted := false
+	err := updateState(func(st *playState) bool {
+		wasMuted = st.mutedAt(time.Now())
+		st.Muted = false
+		st.MutedUntil = time.Time{}
+		return true
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if wasMuted {
+		fmt.Println("Unmuted.")
+	} else {
+		fmt.Println("Not muted.")
+	}
+}
@@ -0,0 +1,230 @@
+package main
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// RemoteConfig sends events to 'claude-bell serve' on another machine
+// instead of playing them here.
+type RemoteConfig struct {
+	URL    string `json:"url"`
+	Secret string `json:"secret"`
+}
+
+// ServeConfig configures 'claude-bell serve'.
+type ServeConfig struct {
+	Addr   string `json:"addr,omitempty"`
+	Secret string `json:"secret"`
+}
+
+const (
+	defaultServeAddr  = "127.0.0.1:7465"
+	remoteTimeout     = 3 * time.Second
+	remoteMaxSkew     = 5 * time.Minute
+	remoteMaxBodySize = 1 << 20
+
+	remoteTimestampHeader = "X-Claude-Bell-Timestamp"
+	remoteSignatureHeader = "X-Claude-Bell-Signature"
+)
+
+// remoteSignature authenticates a request body sent at timestamp (Unix
+// seconds). Signing the timestamp lets the server reject old requests.
+func remoteSignature(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("\n"))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// forwardToRemote sends req to the configured 'claude-bell serve'.
+func forwardToRemote(rc *RemoteConfig, req playRequest) error {
+	if rc.Secret == "" {
+		return errors.New("remote: \"secret\" is not set")
+	}
+	req.TTY = "" // meaningless on the other machine
+	if req.Host == "" {
+		req.Host, _ = os.Hostname() // so the other machine names this one
+	}
+
+	body, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	httpReq, err := http.NewRequest(http.MethodPost, strings.TrimSuffix(rc.URL, "/")+"/play", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("remote: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set(remoteTimestampHeader, timestamp)
+	httpReq.Header.Set(remoteSignatureHeader, remoteSignature(rc.Secret, timestamp, body))
+
+	resp, err := (&http.Client{Timeout: remoteTimeout}).Do(httpReq)
+	if err != nil {
+		return fmt.Errorf("remote: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var dr daemonResponse
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 64<<10)).Decode(&dr); err != nil {
+		return fmt.Errorf("remote: %s", resp.Status)
+	}
+	if !dr.OK {
+		return fmt.Errorf("remote: %s", dr.Error)
+	}
+	return nil
+}
+
+func cmdServe() {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	addr := fs.String("addr", "", "address to listen on (default "+defaultServeAddr+")")
+	fs.Parse(os.Args[2:])
+
+	d := &bellDaemon{}
+	if err := d.reload(); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+	sc := d.cfg.Serve
+	if sc == nil || sc.Secret == "" {
+		fmt.Fprintln(os.Stderr, "error: set \"serve\": {\"secret\": \"...\"} in the config first; remote hosts need the same secret")
+		os.Exit(1)
+	}
+	if *addr == "" {
+		*addr = sc.Addr
+	}
+	if *addr == "" {
+		*addr = defaultServeAddr
+	}
+
+	ln, err := net.Listen("tcp", *addr)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+
+	s := &remoteServer{daemon: d, seen: make(map[string]time.Time)}
+	srv := &http.Server{Handler: s, ReadHeaderTimeout: remoteTimeout}
+
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		<-sigs
+		srv.Close()
+	}()
+
+	go d.watch()
+
+	fmt.Printf("claude-bell serving on http://%s\n", ln.Addr())
+	if err := srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// remoteServer plays events POSTed by forwardToRemote.
+type remoteServer struct {
+	daemon *bellDaemon
+
+	mu   sync.Mutex
+	seen map[string]time.Time // signatures accepted within remoteMaxSkew
+}
+
+func (s *remoteServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/play" {
+		http.NotFound(w, r)
+		return
+	}
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeRemoteResponse(w, http.StatusMethodNotAllowed, "use POST")
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, remoteMaxBodySize))
+	if err != nil {
+		writeRemoteResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	s.daemon.mu.Lock()
+	cfg := s.daemon.cfg
+	s.daemon.mu.Unlock()
+
+	if err := s.verify(cfg.Serve, r.Header, body); err != nil {
+		writeRemoteResponse(w, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	var req playRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		writeRemoteResponse(w, http.StatusBadRequest, fmt.Sprintf("bad request: %v", err))
+		return
+	}
+	req.TTY = ""
+	if err := playEvent(cfg, req, s.daemon.play); err != nil {
+		writeRemoteResponse(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	writeRemoteResponse(w, http.StatusOK, "")
+}
+
+// verify checks the request's signature and timestamp, and that it has not
+// been accepted before.
+func (s *remoteServer) verify(sc *ServeConfig, h http.Header, body []byte) error {
+	if sc == nil || sc.Secret == "" {
+		return errors.New("serve secret is not configured")
+	}
+	timestamp := h.Get(remoteTimestampHeader)
+	sig := h.Get(remoteSignatureHeader)
+	if !hmac.Equal([]byte(sig), []byte(remoteSignature(sc.Secret, timestamp, body))) {
+		return errors.New("bad signature (do the secrets match?)")
+	}
+
+	sec, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return errors.New("bad timestamp")
+	}
+	now := time.Now()
+	if skew := now.Sub(time.Unix(sec, 0)); skew > remoteMaxSkew || skew < -remoteMaxSkew {
+		return fmt.Errorf("timestamp is %s off (are the clocks in sync?)", skew.Round(time.Second))
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for k, t := range s.seen {
+		if now.Sub(t) > 2*remoteMaxSkew {
+			delete(s.seen, k)
+		}
+	}
+	if _, ok := s.seen[sig]; ok {
+		return errors.New("replayed request")
+	}
+	s.seen[sig] = now
+	return nil
+}
+
+func writeRemoteResponse(w http.ResponseWriter, status int, errMsg string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(daemonResponse{OK: errMsg == "", Error: errMsg})
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testRemoteSecret = "correct horse battery staple"
+
+// newRemoteTestServer serves a remoteServer with cfg and the test secret.
+// Without sounds in cfg, accepted requests succeed without making a sound.
+func newRemoteTestServer(t *testing.T, cfg Config) *httptest.Server {
+	t.Helper()
+	t.Setenv("CLAUDE_BELL_CONFIG", t.TempDir())
+	cfg.Serve = &ServeConfig{Secret: testRemoteSecret}
+	d := &bellDaemon{cfg: cfg}
+	srv := httptest.NewServer(&remoteServer{daemon: d, seen: make(map[string]time.Time)})
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// postSigned sends body to the server's /play, signed with secret as of
+// sentAt, and returns the status code.
+func postSigned(t *testing.T, url, secret string, sentAt time.Time, body []byte) int {
+	t.Helper()
+	timestamp := strconv.FormatInt(sentAt.Unix(), 10)
+	req, err := http.NewRequest(http.MethodPost, url+"/play", bytes.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set(remoteTimestampHeader, timestamp)
+	req.Header.Set(remoteSignatureHeader, remoteSignature(secret, timestamp, body))
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	return resp.StatusCode
+}
+
+func testPlayBody(t *testing.T) []byte {
+	t.Helper()
+	body, err := json.Marshal(playRequest{Event: "stop"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return body
+}
+
+func TestForwardToRemoteAccepted(t *testing.T) {
+	srv := newRemoteTestServer(t, Config{})
+	rc := &RemoteConfig{URL: srv.URL + "/", Secret: testRemoteSecret}
+	if err := forwardToRemote(rc, playRequest{Event: "stop", TTY: "/dev/ttys001"}); err != nil {
+		t.Fatalf("forwardToRemote: %v", err)
+	}
+}
+
+func TestRemoteNamesSenderHost(t *testing.T) {
+	hook, got := captureServer(t)
+	srv := newRemoteTestServer(t, Config{Webhook: &WebhookConfig{URL: hook.URL}})
+
+	body, err := json.Marshal(playRequest{Event: "stop", Host: "devbox"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status := postSigned(t, srv.URL, testRemoteSecret, time.Now(), body); status != http.StatusOK {
+		t.Fatalf("got status %d, want %d", status, http.StatusOK)
+	}
+	var sent webhookBody
+	if err := json.Unmarshal((<-got).body, &sent); err != nil {
+		t.Fatal(err)
+	}
+	if sent.Host != "devbox" {
+		t.Errorf("webhook names host %q, want devbox", sent.Host)
+	}
+}
+
+func TestForwardToRemoteWrongSecret(t *testing.T) {
+	srv := newRemoteTestServer(t, Config{})
+	rc := &RemoteConfig{URL: srv.URL, Secret: "wrong"}
+	err := forwardToRemote(rc, playRequest{Event: "stop"})
+	if err == nil || !strings.Contains(err.Error(), "bad signature") {
+		t.Fatalf("got %v, want a bad signature error", err)
+	}
+}
+
+func TestRemoteRejectsTamperedBody(t *testing.T) {
+	srv := newRemoteTestServer(t, Config{})
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	body := testPlayBody(t)
+	req, _ := http.NewRequest(http.MethodPost, srv.URL+"/play", strings.NewReader(`{"event":"limit"}`))
+	req.Header.Set(remoteTimestampHeader, timestamp)
+	req.Header.Set(remoteSignatureHeader, remoteSignature(testRemoteSecret, timestamp, body))
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("got status %d, want %d", resp.StatusCode, http.StatusUnauthorized)
+	}
+}
+
+func TestRemoteTimestampSkew(t *testing.T) {
+	srv := newRemoteTestServer(t, Config{})
+	body := testPlayBody(t)
+	now := time.Now()
+	tests := []struct {
+		name   string
+		sentAt time.Time
+		want   int
+	}{
+		{"recent", now.Add(-time.Minute), http.StatusOK},
+		{"too old", now.Add(-remoteMaxSkew - time.Minute), http.StatusUnauthorized},
+		{"too far ahead", now.Add(remoteMaxSkew + time.Minute), http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := postSigned(t, srv.URL, testRemoteSecret, tt.sentAt, body); got != tt.want {
+				t.Errorf("got status %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRemoteRejectsReplay(t *testing.T) {
+	srv := newRemoteTestServer(t, Config{})
+	body := testPlayBody(t)
+	sentAt := time.Now()
+	if got := postSigned(t, srv.URL, testRemoteSecret, sentAt, body); got != http.StatusOK {
+		t.Fatalf("first request: got status %d, want %d", got, http.StatusOK)
+	}
+	if got := postSigned(t, srv.URL, testRemoteSecret, sentAt, body); got != http.StatusUnauthorized {
+		t.Errorf("replayed request: got status %d, want %d", got, http.StatusUnauthorized)
+	}
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"bufio"
+	"flag"
 	"fmt"
 	"os"
 	"strconv"
@@ -14,14 +15,175 @@ type menuOption struct {
 }
 
 func cmdSetup() {
-	cfg, err := loadConfig()
+	fs := flag.NewFlagSet("setup", flag.ExitOnError)
+	for _, event := range EventNames {
+		fs.String(event, "", fmt.Sprintf("sound for the %s event, or \"none\"", event))
+	}
+	fs.String("volume", "", "playback volume, 0-1 or 0-100")
+	install := fs.Bool("install", false, "install the hooks after saving")
+	scope := fs.String("scope", "user", "settings file for --install: user, project, or local")
+	fs.Parse(os.Args[2:])
+	if fs.NArg() > 0 {
+		fmt.Fprintf(os.Stderr, "error: unexpected argument %q\n", fs.Arg(0))
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	base, err := loadConfig()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "error loading config: %v\n", err)
 		os.Exit(1)
 	}
+	// Setup edits whichever profile is in effect.
+	profile, cfg := activeSettings(base)
+	if base.Profiles[profile] != nil {
+		fmt.Printf("Editing profile %s, which is active.\n\n", profile)
+	}
+
+	// Any flag means a scripted run: apply the flags, never prompt.
+	if fs.NFlag() > 0 {
+		if _, err := claudeSettingsPathForScope(*scope); err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(2)
+		}
+		changes := make(map[string]string)
+		fs.Visit(func(f *flag.Flag) {
+			if f.Name != "install" && f.Name != "scope" {
+				changes[f.Name] = f.Value.String()
+			}
+		})
+		setupFromFlags(base, profile, changes)
+		if *install {
+			fmt.Println()
+			installHooks(installOptions{scope: *scope, yes: true})
+		}
+		return
+	}
 
 	customSounds, _ := loadCustomSounds()
 	reader := bufio.NewReader(os.Stdin)
+
+	updated, ok, err := setupScreen(cfg, customSounds)
+	if err != nil {
+		// No usable terminal: fall back to line prompts.
+		updated, ok = promptEvents(cfg, customSounds, reader)
+	}
+	if !ok {
+		fmt.Fprintln(os.Stderr, "setup canceled")
+		os.Exit(1)
+	}
+
+	fmt.Println("Summary")
+	fmt.Println("-------")
+	for _, event := range EventNames {
+		selected := getConfigField(updated, event)
+		if selected == "" {
+			selected = "(none)"
+		}
+		if selected != "(none)" {
+			selected += fmt.Sprintf(" at %s", formatVolume(eventVolume(updated, event)))
+		}
+		fmt.Printf("  %-14s %s\n", event+":", selected)
+	}
+	fmt.Printf("  %-14s %s\n", "volume:", formatVolume(updated.Volume))
+	fmt.Println()
+
+	if !promptYesNo(reader, "Save these settings? [Y/n]: ", true) {
+		fmt.Println("No changes saved.")
+		return
+	}
+
+	if err := saveConfig(storeProfileSettings(base, profile, updated)); err != nil {
+		fmt.Fprintf(os.Stderr, "error saving config: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Println("Config saved.")
+	fmt.Println("Next step: run 'claude-bell install' to add hooks to Claude Code.")
+}
+
+// setupFromFlags applies the sounds and volume given as setup flags, keyed by
+// flag name, to the named profile of base and saves the config. Unknown
+// sounds and invalid volumes exit with status 2 before anything is written.
+func setupFromFlags(base Config, profile string, changes map[string]string) {
+	cfg := base.withProfile(profile)
+	customSounds, err := loadCustomSounds()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error loading custom sounds: %v\n", err)
+		os.Exit(1)
+	}
+
+	var problems []string
+	for _, event := range EventNames {
+		name, ok := changes[event]
+		if !ok {
+			continue
+		}
+		if strings.EqualFold(name, "none") || name == "" {
+			cfg = setConfigField(cfg, event, "")
+			continue
+		}
+		sound, ok := resolveSoundName(event, name, customSounds)
+		if !ok {
+			problems = append(problems, fmt.Sprintf("--%s: unknown sound %q (available: %s)",
+				event, name, strings.Join(soundNames(event, customSounds), ", ")))
+			continue
+		}
+		cfg = setConfigField(cfg, event, sound)
+	}
+	if v, ok := changes["volume"]; ok {
+		volume, err := parseVolumeArg(v)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("--volume: %v", err))
+		}
+		cfg.Volume = volume
+	}
+
+	if len(problems) > 0 {
+		for _, p := range problems {
+			fmt.Fprintf(os.Stderr, "error: %s\n", p)
+		}
+		os.Exit(2)
+	}
+
+	if err := saveConfig(storeProfileSettings(base, profile, cfg)); err != nil {
+		fmt.Fprintf(os.Stderr, "error saving config: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("Config saved to %s\n", displayPath(configPath()))
+	for _, event := range EventNames {
+		selected := getConfigField(cfg, event)
+		if selected == "" {
+			selected = "(none)"
+		}
+		fmt.Printf("  %-14s %s\n", event+":", selected)
+	}
+	fmt.Printf("  %-14s %s\n", "volume:", formatVolume(cfg.Volume))
+}
+
+// resolveSoundName finds the preset or custom sound for event matching name
+// in any case, returning its canonical name.
+func resolveSoundName(event, name string, customSounds []CustomSound) (string, bool) {
+	for _, opt := range buildEventOptions(event, customSounds) {
+		if strings.EqualFold(opt.name, name) {
+			return opt.name, true
+		}
+	}
+	return "", false
+}
+
+func soundNames(event string, customSounds []CustomSound) []string {
+	var names []string
+	for _, opt := range buildEventOptions(event, customSounds) {
+		names = append(names, opt.name)
+	}
+	return names
+}
+
+// promptEvents asks for each event's sound with numbered line prompts. It
+// reports false if input ends first.
+func promptEvents(cfg Config, customSounds []CustomSound, reader *bufio.Reader) (Config, bool) {
 	updated := cfg
 
 	fmt.Println("claude-bell setup")
@@ -42,6 +204,7 @@ func cmdSetup() {
 		} else {
 			fmt.Printf("  Current: %s\n", current)
 		}
+		fmt.Printf("  Volume: %s%s\n", formatVolume(eventVolume(updated, event)), volumeSource(updated, event))
 		fmt.Println()
 
 		for i, opt := range options {
@@ -57,39 +220,14 @@ func cmdSetup() {
 		fmt.Println("  s) Skip (no sound)")
 		fmt.Println()
 
-		choice, ok := promptEventChoice(reader, event, current, options, updated.Volume)
+		choice, ok := promptEventChoice(reader, event, current, options, eventVolume(updated, event))
 		if !ok {
-			fmt.Fprintln(os.Stderr, "setup canceled")
-			os.Exit(1)
+			return cfg, false
 		}
 		updated = setConfigField(updated, event, choice)
 		fmt.Println()
 	}
-
-	fmt.Println("Summary")
-	fmt.Println("-------")
-	for _, event := range EventNames {
-		selected := getConfigField(updated, event)
-		if selected == "" {
-			selected = "(none)"
-		}
-		fmt.Printf("  %-14s %s\n", event+":", selected)
-	}
-	fmt.Printf("  %-14s %s\n", "volume:", formatVolume(updated.Volume))
-	fmt.Println()
-
-	if !promptYesNo(reader, "Save these settings? [Y/n]: ", true) {
-		fmt.Println("No changes saved.")
-		return
-	}
-
-	if err := saveConfig(updated); err != nil {
-		fmt.Fprintf(os.Stderr, "error saving config: %v\n", err)
-		os.Exit(1)
-	}
-
-	fmt.Println("Config saved.")
-	fmt.Println("Next step: run 'claude-bell install' to add hooks to Claude Code.")
+	return updated, true
 }
 
 func buildEventOptions(event string, customSounds []CustomSound) []menuOption {
@@ -163,11 +301,19 @@ func promptEventChoice(reader *bufio.Reader, event, current string, options []me
 }
 
 func promptYesNo(reader *bufio.Reader, prompt string, defaultYes bool) bool {
+	return askYesNo(reader, prompt, defaultYes, defaultYes)
+}
+
+// askYesNo is promptYesNo with a separate answer for end of input.
+func askYesNo(reader *bufio.Reader, prompt string, defaultYes, atEOF bool) bool {
 	for {
 		fmt.Print(prompt)
 		input, err := reader.ReadString('\n')
 		if err != nil && len(input) == 0 {
-			return defaultYes
+			if !atEOF {
+				fmt.Println()
+			}
+			return atEOF
 		}
 		s := strings.ToLower(strings.TrimSpace(input))
 
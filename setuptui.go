@@ -0,0 +1,349 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"math"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// Keys recognized by the setup screen.
+const (
+	keyNone = iota
+	keyUp
+	keyDown
+	keyLeft
+	keyRight
+	keyEnter
+	keyReplay
+	keyBack
+	keyQuit
+)
+
+// volumeStep is how much left and right change the volume.
+const volumeStep = 0.05
+
+// sidePanelMinWidth is the narrowest terminal that gets the tone panel beside
+// the list rather than below it.
+const sidePanelMinWidth = 72
+
+// setupUI is the state of the full-screen setup.
+type setupUI struct {
+	cfg          Config
+	customSounds []CustomSound
+	step         int // index into EventNames
+	cursor       int // index into options; len(options) is "(none)"
+	options      []menuOption
+	preview      *exec.Cmd
+	status       string
+	width        int
+}
+
+// setupScreen runs setup as a full-screen, keyboard-driven UI: the cursor
+// previews sounds as it moves and left/right set the event's volume. It
+// returns an error, before drawing anything, when stdin and stdout are not a
+// terminal that can be put in raw mode; ok is false if the user quit.
+func setupScreen(cfg Config, customSounds []CustomSound) (updated Config, ok bool, err error) {
+	if !isTerminal(os.Stdin) || !isTerminal(os.Stdout) {
+		return cfg, false, errors.New("not a terminal")
+	}
+	restore, err := rawMode()
+	if err != nil {
+		return cfg, false, err
+	}
+	fmt.Print("\x1b[?1049h\x1b[?25l") // alternate screen, hidden cursor
+	defer func() {
+		fmt.Print("\x1b[?25h\x1b[?1049l")
+		restore()
+	}()
+
+	ui := &setupUI{cfg: cfg, customSounds: customSounds, width: terminalWidth()}
+	defer ui.stopPreview()
+	ui.enterStep(0)
+
+	buf := make([]byte, 16)
+	for {
+		ui.draw()
+		n, err := os.Stdin.Read(buf)
+		if err != nil {
+			return cfg, false, nil
+		}
+		ui.status = ""
+
+		for _, key := range readKeys(buf[:n]) {
+			switch key {
+			case keyUp:
+				ui.move(-1)
+			case keyDown:
+				ui.move(1)
+			case keyLeft:
+				ui.adjustVolume(-volumeStep)
+			case keyRight:
+				ui.adjustVolume(volumeStep)
+			case keyReplay:
+				ui.startPreview()
+			case keyEnter:
+				ui.cfg = setConfigField(ui.cfg, ui.event(), ui.selected())
+				if ui.step == len(EventNames)-1 {
+					return ui.cfg, true, nil
+				}
+				ui.enterStep(ui.step + 1)
+			case keyBack:
+				if ui.step > 0 {
+					ui.enterStep(ui.step - 1)
+				}
+			case keyQuit:
+				return cfg, false, nil
+			}
+		}
+	}
+}
+
+// readKeys decodes the keypresses in one read, which can hold several when
+// a key is held down or input is pasted.
+func readKeys(b []byte) []int {
+	var keys []int
+	for len(b) > 0 {
+		n := 1
+		if len(b) >= 3 && b[0] == '\x1b' && (b[1] == '[' || b[1] == 'O') {
+			n = 3 // arrow keys
+		}
+		keys = append(keys, readKey(b[:n]))
+		b = b[n:]
+	}
+	return keys
+}
+
+// readKey decodes one keypress, including arrow-key escape sequences.
+func readKey(b []byte) int {
+	switch string(b) {
+	case "\x1b[A", "\x1bOA", "k":
+		return keyUp
+	case "\x1b[B", "\x1bOB", "j":
+		return keyDown
+	case "\x1b[D", "\x1bOD", "h", "-":
+		return keyLeft
+	case "\x1b[C", "\x1bOC", "l", "+", "=":
+		return keyRight
+	case "\r", "\n":
+		return keyEnter
+	case " ", "p":
+		return keyReplay
+	case "b", "\x7f", "\b":
+		return keyBack
+	case "q", "\x1b", "\x03", "\x04": // q, Esc, Ctrl-C, Ctrl-D
+		return keyQuit
+	}
+	return keyNone
+}
+
+func (ui *setupUI) event() string {
+	return EventNames[ui.step]
+}
+
+// selected returns the sound under the cursor, or "" for no sound.
+func (ui *setupUI) selected() string {
+	if ui.cursor >= len(ui.options) {
+		return ""
+	}
+	return ui.options[ui.cursor].name
+}
+
+// enterStep shows the given event with the cursor on its current sound.
+func (ui *setupUI) enterStep(step int) {
+	ui.stopPreview()
+	ui.step = step
+	ui.options = buildEventOptions(ui.event(), ui.customSounds)
+	ui.cursor = len(ui.options)
+	current := getConfigField(ui.cfg, ui.event())
+	for i, opt := range ui.options {
+		if strings.EqualFold(opt.name, current) {
+			ui.cursor = i
+		}
+	}
+}
+
+func (ui *setupUI) move(delta int) {
+	n := len(ui.options) + 1 // the options and "(none)"
+	ui.cursor = (ui.cursor + delta + n) % n
+	ui.startPreview()
+}
+
+// adjustVolume changes the current event's volume and replays the preview
+// at the new level.
+func (ui *setupUI) adjustVolume(delta float64) {
+	event := ui.event()
+	v := clampVolume(math.Round((eventVolume(ui.cfg, event)+delta)*100) / 100)
+	if v == eventVolume(ui.cfg, event) {
+		ui.startPreview()
+		return
+	}
+	volumes := maps.Clone(ui.cfg.Volumes)
+	if volumes == nil {
+		volumes = make(map[string]float64)
+	}
+	volumes[event] = v
+	ui.cfg.Volumes = volumes
+	ui.startPreview()
+}
+
+// startPreview plays the sound under the cursor, cutting off any preview
+// still playing.
+func (ui *setupUI) startPreview() {
+	ui.stopPreview()
+	name := ui.selected()
+	if name == "" {
+		return
+	}
+	path, err := ensureSound(ui.event(), name)
+	if err != nil {
+		ui.status = fmt.Sprintf("error: %v", err)
+		return
+	}
+	cmd, err := startSound(path, eventVolume(ui.cfg, ui.event()))
+	if err != nil {
+		ui.status = fmt.Sprintf("playback error: %v", err)
+		return
+	}
+	ui.preview = cmd
+	go cmd.Wait()
+}
+
+func (ui *setupUI) stopPreview() {
+	if ui.preview != nil {
+		ui.preview.Process.Kill()
+		ui.preview = nil
+	}
+}
+
+func (ui *setupUI) draw() {
+	event := ui.event()
+	current := getConfigField(ui.cfg, event)
+
+	var list []string
+	for i := 0; i <= len(ui.options); i++ {
+		label := "(none)"
+		if i < len(ui.options) {
+			label = ui.options[i].name
+			if ui.options[i].custom {
+				label += " (custom)"
+			}
+			if strings.EqualFold(ui.options[i].name, current) {
+				label += " [current]"
+			}
+		} else if current == "" {
+			label += " [current]"
+		}
+		if i == ui.cursor {
+			list = append(list, fmt.Sprintf("\x1b[7m> %-32s\x1b[0m", label))
+		} else {
+			list = append(list, fmt.Sprintf("  %-32s", label))
+		}
+	}
+	panel := tonePanel(event, ui.selected(), ui.customSounds)
+
+	var b strings.Builder
+	b.WriteString("\x1b[H\x1b[2J")
+	fmt.Fprintf(&b, "claude-bell setup  [%d/%d] %s\n", ui.step+1, len(EventNames), event)
+	fmt.Fprintf(&b, "%s\n\n", EventDescriptions[event])
+	if ui.width >= sidePanelMinWidth {
+		for i := 0; i < max(len(list), len(panel)); i++ {
+			left := strings.Repeat(" ", 34)
+			if i < len(list) {
+				left = list[i]
+			}
+			right := ""
+			if i < len(panel) {
+				right = panel[i]
+			}
+			fmt.Fprintf(&b, "%s │ %s\n", left, right)
+		}
+	} else {
+		for _, line := range list {
+			b.WriteString(line + "\n")
+		}
+		b.WriteString("\n")
+		for _, line := range panel {
+			b.WriteString(line + "\n")
+		}
+	}
+	fmt.Fprintf(&b, "\nVolume: ◀ %s ▶%s\n\n", formatVolume(eventVolume(ui.cfg, event)), volumeSource(ui.cfg, event))
+	b.WriteString("↑↓ choose  ←→ volume  space replay  Enter select  b back  q quit\n")
+	if ui.status != "" {
+		fmt.Fprintf(&b, "\n%s\n", ui.status)
+	}
+	fmt.Print(strings.ReplaceAll(b.String(), "\n", "\r\n"))
+}
+
+// tonePanel describes the tones of a sound, one line per tone with a bar
+// for its length.
+func tonePanel(event, name string, customSounds []CustomSound) []string {
+	if name == "" {
+		return []string{"No sound"}
+	}
+	tones, ok := lookupTones(event, name, customSounds)
+	if !ok {
+		return []string{"Unknown sound"}
+	}
+	lines := []string{"Tones"}
+	var total float64
+	for _, t := range tones {
+		total += t.Duration
+		width := max(1, int(math.Round(t.Duration*40)))
+		if t.Freq <= 0 {
+			lines = append(lines, fmt.Sprintf("  %-4s %8s %5.0f ms  %s", "rest", "", t.Duration*1000, strings.Repeat("·", width)))
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("  %-4s %5.0f Hz %5.0f ms  %s", noteName(t.Freq), t.Freq, t.Duration*1000, strings.Repeat("█", width)))
+	}
+	lines = append(lines, "", fmt.Sprintf("Total %.0f ms", total*1000))
+	return lines
+}
+
+// noteName returns the nearest equal-tempered note, e.g. "C5" for 523.25 Hz.
+func noteName(freq float64) string {
+	names := [12]string{"C", "C#", "D", "D#", "E", "F", "F#", "G", "G#", "A", "A#", "B"}
+	midi := int(math.Round(69 + 12*math.Log2(freq/440)))
+	return names[(midi%12+12)%12] + strconv.Itoa(midi/12-1)
+}
+
+// rawMode switches the terminal on stdin to unbuffered, unechoed input with
+// stty and returns a function that restores the previous settings.
+func rawMode() (restore func(), err error) {
+	saved, err := stty("-g")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := stty("-icanon", "-echo", "-isig", "-ixon", "min", "1", "time", "0"); err != nil {
+		return nil, err
+	}
+	return func() { stty(strings.TrimSpace(saved)) }, nil
+}
+
+// terminalWidth returns the number of columns, or 80 if unknown.
+func terminalWidth() int {
+	out, err := stty("size")
+	if err != nil {
+		return 80
+	}
+	fields := strings.Fields(out)
+	if len(fields) != 2 {
+		return 80
+	}
+	cols, err := strconv.Atoi(fields[1])
+	if err != nil || cols <= 0 {
+		return 80
+	}
+	return cols
+}
+
+func stty(args ...string) (string, error) {
+	cmd := exec.Command("stty", args...)
+	cmd.Stdin = os.Stdin
+	out, err := cmd.Output()
+	return string(out), err
+}
@@ -97,6 +97,14 @@ var EventPresets = map[string][]SoundPreset{
 // EventNames defines the display order for events.
 var EventNames = []string{"stop", "notification", "limit"}
 
+// EventPriorities ranks events for the "preempt" playback policy; a higher
+// priority sound cuts off a lower priority one.
+var EventPriorities = map[string]int{
+	"stop":         1,
+	"notification": 2,
+	"limit":        3,
+}
+
 // EventDescriptions provides a human-readable description for each event.
 var EventDescriptions = map[string]string{
 	"stop":         "Task complete - Claude finishes responding",
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// SpeakConfig announces an event with a local text-to-speech engine, after
+// the event's sound if it has one. Phrase is a template over eventContext.
+type SpeakConfig struct {
+	Phrase string  `json:"phrase,omitempty"`
+	Engine string  `json:"engine,omitempty"` // say, espeak-ng, espeak, festival, or piper; default is the first installed
+	Voice  string  `json:"voice,omitempty"`  // engine voice name, or the model file for piper
+	Rate   float64 `json:"rate,omitempty"`   // words per minute (say, espeak)
+}
+
+const defaultSpeakPhrase = "{{if .Project}}{{.Project}}: {{end}}{{.Message}}"
+
+// speechEngine is a command-line TTS program. start begins speaking text at
+// volume, which is between 0 and 1.
+type speechEngine struct {
+	name  string
+	start func(bin string, sc SpeakConfig, text string, volume float64) (*exec.Cmd, error)
+}
+
+// speechEngines lists supported engines in order of preference.
+var speechEngines = []speechEngine{
+	{name: "say", start: startSay}, // macOS
+	{name: "espeak-ng", start: startEspeak},
+	{name: "espeak", start: startEspeak},
+	{name: "festival", start: startFestival},
+	{name: "piper", start: startPiper},
+}
+
+// findSpeechEngine returns the named engine, or the first one installed when
+// name is empty.
+func findSpeechEngine(name string) (speechEngine, string, error) {
+	names := make([]string, len(speechEngines))
+	for i, e := range speechEngines {
+		names[i] = e.name
+		if name != "" && e.name != name {
+			continue
+		}
+		if bin, err := exec.LookPath(e.name); err == nil {
+			return e, bin, nil
+		} else if name != "" {
+			return speechEngine{}, "", fmt.Errorf("speech engine %s not found", name)
+		}
+	}
+	if name != "" {
+		return speechEngine{}, "", fmt.Errorf("unknown speech engine %q (use %s)", name, strings.Join(names, ", "))
+	}
+	return speechEngine{}, "", fmt.Errorf("no speech engine found (need one of: %s)", strings.Join(names, ", "))
+}
+
+// speakPhrase renders the phrase to speak for an event.
+func speakPhrase(sc *SpeakConfig, ctx eventContext) string {
+	phrase := sc.Phrase
+	if phrase == "" {
+		phrase = defaultSpeakPhrase
+	}
+	return strings.TrimSpace(ctx.expand(phrase))
+}
+
+// startSpeech starts speaking text with the configured engine.
+func startSpeech(sc *SpeakConfig, text string, volume float64) (*exec.Cmd, error) {
+	engine, bin, err := findSpeechEngine(sc.Engine)
+	if err != nil {
+		return nil, err
+	}
+	return engine.start(bin, *sc, text, clampVolume(volume))
+}
+
+func startSay(bin string, sc SpeakConfig, text string, volume float64) (*exec.Cmd, error) {
+	args := []string{}
+	if sc.Voice != "" {
+		args = append(args, "-v", sc.Voice)
+	}
+	if sc.Rate > 0 {
+		args = append(args, "-r", strconv.Itoa(int(sc.Rate)))
+	}
+	// say has no volume flag but honors an embedded volume command.
+	args = append(args, fmt.Sprintf("[[volm %.2f]] %s", volume, text))
+	return startCommand(exec.Command(bin, args...))
+}
+
+func startEspeak(bin string, sc SpeakConfig, text string, volume float64) (*exec.Cmd, error) {
+	// Amplitude runs from 0 to 200, with 100 as espeak's default.
+	args := []string{"-a", strconv.Itoa(int(volume * 100))}
+	if sc.Voice != "" {
+		args = append(args, "-v", sc.Voice)
+	}
+	if sc.Rate > 0 {
+		args = append(args, "-s", strconv.Itoa(int(sc.Rate)))
+	}
+	args = append(args, "--", text)
+	return startCommand(exec.Command(bin, args...))
+}
+
+func startFestival(bin string, sc SpeakConfig, text string, volume float64) (*exec.Cmd, error) {
+	cmd := exec.Command(bin, "--tts")
+	cmd.Stdin = strings.NewReader(text)
+	return startCommand(cmd)
+}
+
+// startPiper synthesizes text to a WAV file and plays it with the audio
+// backend, since piper only writes audio. Voice must name a .onnx model.
+func startPiper(bin string, sc SpeakConfig, text string, volume float64) (*exec.Cmd, error) {
+	if sc.Voice == "" {
+		return nil, fmt.Errorf("piper needs \"voice\" set to a model file (.onnx)")
+	}
+	if err := os.MkdirAll(soundsDir(), 0755); err != nil {
+		return nil, err
+	}
+	path := filepath.Join(soundsDir(), "speech.wav")
+	cmd := exec.Command(bin, "--model", sc.Voice, "--output_file", path)
+	cmd.Stdin = strings.NewReader(text)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return nil, fmt.Errorf("piper: %v: %s", err, strings.TrimSpace(string(out)))
+	}
+	return startSound(path, volume)
+}
+
+func startCommand(cmd *exec.Cmd) (*exec.Cmd, error) {
+	cmd.Stderr = os.Stderr
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	return cmd, nil
+}
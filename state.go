@@ -0,0 +1,87 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"time"
+)
+
+// playState is shared between claude-bell processes through statePath.
+type playState struct {
+	LastPlayed map[string]time.Time `json:"last_played,omitempty"` // per event
+	LastAny    time.Time            `json:"last_any,omitempty"`
+
+	// Muted is set by 'claude-bell mute'; a zero MutedUntil mutes until
+	// 'claude-bell unmute'.
+	Muted      bool      `json:"muted,omitempty"`
+	MutedUntil time.Time `json:"muted_until,omitempty"`
+}
+
+// updateState loads the state under an exclusive lock, passes it to fn, and
+// writes it back if fn reports a change.
+func updateState(fn func(st *playState) (changed bool)) error {
+	lock, err := lockFile(statePath() + ".lock")
+	if err != nil {
+		return err
+	}
+	defer unlockFile(lock)
+
+	st, err := readState()
+	if err != nil {
+		return err
+	}
+	if !fn(&st) {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(st, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(statePath(), data)
+}
+
+func readState() (playState, error) {
+	var st playState
+	data, err := os.ReadFile(statePath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return st, nil
+		}
+		return st, err
+	}
+	if err := json.Unmarshal(data, &st); err != nil {
+		// A corrupt state file only holds timestamps; start over.
+		return playState{}, nil
+	}
+	return st, nil
+}
+
+// claimPlaySlot records that event is about to play and returns true, or
+// returns false without recording anything if the event's cooldown or the
+// global rate limit has not yet elapsed.
+func claimPlaySlot(cfg Config, event string, now time.Time) (bool, error) {
+	allowed := false
+	err := updateState(func(st *playState) bool {
+		if cd := cfg.Cooldowns[event]; cd > 0 {
+			if last, ok := st.LastPlayed[event]; ok && now.Sub(last) < seconds(cd) {
+				return false
+			}
+		}
+		if cfg.RateLimit > 0 && now.Sub(st.LastAny) < seconds(cfg.RateLimit) {
+			return false
+		}
+		if st.LastPlayed == nil {
+			st.LastPlayed = make(map[string]time.Time)
+		}
+		st.LastPlayed[event] = now
+		st.LastAny = now
+		allowed = true
+		return true
+	})
+	return allowed, err
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
@@ -0,0 +1,213 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// Terminal alerts, written to the terminal Claude Code runs in so that the
+// emulator on the user's side reacts even over SSH.
+const (
+	alertBell   = "bell"   // BEL; most terminals and tmux flag the window
+	alertOSC9   = "osc9"   // OSC 9 notification (iTerm2, WezTerm, Windows Terminal, ...)
+	alertOSC777 = "osc777" // OSC 777 notification (urxvt, foot, Ghostty, ...)
+	alertTmux   = "tmux"   // tmux display-message in the session's pane
+)
+
+// TerminalConfig enables terminal alerts for an event. Message is a template
+// over eventContext.
+type TerminalConfig struct {
+	Alerts  []string `json:"alerts,omitempty"` // default: bell
+	Message string   `json:"message,omitempty"`
+}
+
+const defaultTerminalMessage = "{{if .Project}}{{.Project}}: {{end}}{{.Message}}"
+
+// maxTTYSearchDepth bounds the walk up the process tree looking for a tty.
+const maxTTYSearchDepth = 16
+
+// sendTerminalAlert writes the configured alerts to the terminal of the
+// Claude Code session: tty if known, otherwise the one openSessionTTY finds.
+// silent leaves out the bell, for events that should not make a sound.
+func sendTerminalAlert(tc *TerminalConfig, ctx eventContext, tty string, silent bool) error {
+	t := TerminalConfig{}
+	if tc != nil {
+		t = *tc
+	}
+	if len(t.Alerts) == 0 {
+		t.Alerts = []string{alertBell}
+	}
+	if t.Message == "" {
+		t.Message = defaultTerminalMessage
+	}
+	msg := stripControl(ctx.expand(t.Message))
+
+	var seq strings.Builder
+	var errs []error
+	for _, alert := range t.Alerts {
+		switch alert {
+		case alertBell:
+			if !silent {
+				seq.WriteString("\a")
+			}
+		case alertOSC9:
+			seq.WriteString(tmuxPassthrough("\x1b]9;" + msg + "\a"))
+		case alertOSC777:
+			seq.WriteString(tmuxPassthrough("\x1b]777;notify;Claude Code;" + msg + "\a"))
+		case alertTmux:
+			errs = append(errs, tmuxDisplayMessage(msg))
+		default:
+			errs = append(errs, fmt.Errorf("unknown terminal alert %q (use bell, osc9, osc777, or tmux)", alert))
+		}
+	}
+
+	if seq.Len() > 0 {
+		f, err := openSessionTTY(tty)
+		if err != nil {
+			errs = append(errs, err)
+		} else {
+			_, err = f.WriteString(seq.String())
+			f.Close()
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// tmuxPassthrough wraps an escape sequence so tmux forwards it to the outer
+// terminal (this needs "set -g allow-passthrough on" in tmux 3.3 and later).
+// Outside tmux it returns seq unchanged.
+func tmuxPassthrough(seq string) string {
+	if os.Getenv("TMUX") == "" {
+		return seq
+	}
+	return "\x1bPtmux;" + strings.ReplaceAll(seq, "\x1b", "\x1b\x1b") + "\x1b\\"
+}
+
+// tmuxDisplayMessage shows msg in the status line of the client viewing the
+// session's pane.
+func tmuxDisplayMessage(msg string) error {
+	if os.Getenv("TMUX") == "" {
+		return errors.New("tmux alert: not running inside tmux")
+	}
+	args := []string{"display-message"}
+	if pane := os.Getenv("TMUX_PANE"); pane != "" {
+		args = append(args, "-t", pane)
+	}
+	// A literal message must not be parsed as a tmux format.
+	args = append(args, strings.ReplaceAll(msg, "#", "##"))
+	if out, err := exec.Command("tmux", args...).CombinedOutput(); err != nil {
+		return fmt.Errorf("tmux display-message: %v: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// stripControl removes control characters so text from the hook payload
+// cannot end or inject escape sequences.
+func stripControl(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r < 0x20 || r == 0x7f || (r >= 0x80 && r < 0xa0) {
+			if r == '\n' || r == '\t' {
+				return ' '
+			}
+			return -1
+		}
+		return r
+	}, s)
+}
+
+// openSessionTTY opens the terminal the Claude Code session runs in for
+// writing, preferring path when it is set. Claude captures hook stdout and may
+// start hooks without a controlling terminal, so after /dev/tty it looks for
+// a terminal on the standard streams of each ancestor process.
+func openSessionTTY(path string) (*os.File, error) {
+	if path != "" {
+		return os.OpenFile(path, os.O_WRONLY, 0)
+	}
+	if f, err := os.OpenFile("/dev/tty", os.O_WRONLY, 0); err == nil {
+		return f, nil
+	}
+	if path = sessionTTYPath(); path != "" {
+		return os.OpenFile(path, os.O_WRONLY, 0)
+	}
+	return nil, errors.New("no terminal found for the Claude Code session")
+}
+
+// sessionTTYPath returns the device path of the first terminal attached to
+// this process or one of its ancestors, or "" if there is none.
+func sessionTTYPath() string {
+	pid := os.Getpid()
+	for i := 0; i < maxTTYSearchDepth && pid > 1; i++ {
+		tty, ppid, err := processTTY(pid)
+		if err != nil {
+			return ""
+		}
+		if tty != "" {
+			return tty
+		}
+		pid = ppid
+	}
+	return ""
+}
+
+// processTTY returns the terminal device attached to a process's standard
+// streams, if any, and its parent pid. It reads /proc where available and
+// asks ps otherwise (macOS).
+func processTTY(pid int) (tty string, ppid int, err error) {
+	if _, err := os.Stat("/proc/self/stat"); err == nil {
+		return procTTY(pid)
+	}
+
+	out, err := exec.Command("ps", "-o", "tty=,ppid=", "-p", strconv.Itoa(pid)).Output()
+	if err != nil {
+		return "", 0, err
+	}
+	fields := strings.Fields(string(out))
+	if len(fields) != 2 {
+		return "", 0, fmt.Errorf("unexpected ps output %q", out)
+	}
+	ppid, err = strconv.Atoi(fields[1])
+	if err != nil {
+		return "", 0, err
+	}
+	if name := fields[0]; name != "??" && name != "-" {
+		if !strings.HasPrefix(name, "tty") && !strings.HasPrefix(name, "pts/") {
+			name = "tty" + name
+		}
+		tty = "/dev/" + name
+	}
+	return tty, ppid, nil
+}
+
+func procTTY(pid int) (tty string, ppid int, err error) {
+	dir := "/proc/" + strconv.Itoa(pid)
+	stat, err := os.ReadFile(dir + "/stat")
+	if err != nil {
+		return "", 0, err
+	}
+	// The command name in field 2 may contain spaces; fields after it are
+	// state, ppid, ...
+	rest := stat[strings.LastIndexByte(string(stat), ')')+1:]
+	fields := strings.Fields(string(rest))
+	if len(fields) < 2 {
+		return "", 0, fmt.Errorf("unexpected %s/stat", dir)
+	}
+	if ppid, err = strconv.Atoi(fields[1]); err != nil {
+		return "", 0, err
+	}
+
+	for _, fd := range []string{"0", "1", "2"} {
+		target, err := os.Readlink(dir + "/fd/" + fd)
+		if err != nil {
+			continue
+		}
+		if strings.HasPrefix(target, "/dev/pts/") || (strings.HasPrefix(target, "/dev/tty") && target != "/dev/tty") {
+			return target, ppid, nil
+		}
+	}
+	return "", ppid, nil
+}
@@ -0,0 +1,298 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// configError lists everything wrong with a config file.
+type configError struct {
+	path     string
+	problems []string
+}
+
+func (e *configError) Error() string {
+	return fmt.Sprintf("config %s:\n  %s", displayPath(e.path), strings.Join(e.problems, "\n  "))
+}
+
+// decodeConfig decodes a current-version config into cfg, collecting unknown
+// keys, type mismatches and invalid values into a *configError.
+func decodeConfig(data []byte, cfg *Config) error {
+	decoded, err := decodeOrderedJSON(data)
+	if err != nil {
+		return fmt.Errorf("config %s: %w", displayPath(configPath()), err)
+	}
+
+	var problems []string
+	unknownKeys(decoded, reflect.TypeOf(*cfg), "", &problems)
+
+	if err := json.Unmarshal(data, cfg); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) {
+			problems = append(problems, fmt.Sprintf("%s: expected %s, got %s", typeErr.Field, jsonTypeName(typeErr.Type), typeErr.Value))
+		} else {
+			problems = append(problems, err.Error())
+		}
+	} else {
+		problems = append(problems, cfg.problems()...)
+	}
+
+	if len(problems) > 0 {
+		return &configError{path: configPath(), problems: problems}
+	}
+	return nil
+}
+
+// unknownKeys reports object keys in v that have no matching json field in
+// t, recursing through structs, maps and slices.
+func unknownKeys(v any, t reflect.Type, path string, problems *[]string) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Struct:
+		obj, ok := v.(*jsonObject)
+		if !ok {
+			return // a type error, reported by json.Unmarshal
+		}
+		for _, key := range obj.Keys() {
+			field, ok := jsonField(t, key)
+			if !ok {
+				*problems = append(*problems, fmt.Sprintf("unknown key %q", joinPath(path, key)))
+				continue
+			}
+			child, _ := obj.Get(key)
+			unknownKeys(child, field.Type, joinPath(path, key), problems)
+		}
+	case reflect.Map:
+		if obj, ok := v.(*jsonObject); ok {
+			for _, key := range obj.Keys() {
+				child, _ := obj.Get(key)
+				unknownKeys(child, t.Elem(), joinPath(path, key), problems)
+			}
+		}
+	case reflect.Slice:
+		if arr, ok := v.([]any); ok {
+			for i, child := range arr {
+				unknownKeys(child, t.Elem(), fmt.Sprintf("%s[%d]", path, i), problems)
+			}
+		}
+	}
+}
+
+// jsonField finds the struct field encoded under key.
+func jsonField(t reflect.Type, key string) (reflect.StructField, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "" && f.Anonymous {
+			// Embedded struct fields are encoded inline.
+			if inner, ok := jsonField(f.Type, key); ok {
+				return inner, true
+			}
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		if name == key && name != "-" {
+			return f, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+func jsonTypeName(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.String:
+		return "a string"
+	case reflect.Bool:
+		return "true or false"
+	case reflect.Int, reflect.Int64, reflect.Float64:
+		return "a number"
+	case reflect.Map, reflect.Struct, reflect.Pointer:
+		return "an object"
+	case reflect.Slice:
+		return "a list"
+	}
+	return t.String()
+}
+
+// problems checks the values in a decoded config.
+func (cfg Config) problems() []string {
+	var p []string
+	add := func(format string, args ...any) {
+		p = append(p, fmt.Sprintf(format, args...))
+	}
+	volume := func(key string, v float64) {
+		if v < 0 || v > 1 {
+			add("%s: volume %g is outside 0-1", key, v)
+		}
+	}
+	events := func(key string, names []string) {
+		sort.Strings(names)
+		for _, e := range names {
+			if !isEventName(e) {
+				add("%s: unknown event %q (use %s)", key, e, strings.Join(EventNames, ", "))
+			}
+		}
+	}
+
+	events("events", mapKeys(cfg.Events))
+	volume("volume", cfg.Volume)
+	events("volumes", mapKeys(cfg.Volumes))
+	for e, v := range cfg.Volumes {
+		volume("volumes."+e, v)
+	}
+	if cfg.BackupKeep < 0 {
+		add("backup_keep: must not be negative")
+	}
+
+	events("cooldowns", mapKeys(cfg.Cooldowns))
+	for e, v := range cfg.Cooldowns {
+		if v < 0 {
+			add("cooldowns.%s: must not be negative", e)
+		}
+	}
+	if cfg.RateLimit < 0 {
+		add("rate_limit: must not be negative")
+	}
+
+	switch cfg.PlaybackPolicy {
+	case "", policyQueue, policyDrop, policyPreempt:
+	default:
+		add("playback_policy: unknown policy %q (use queue, drop, or preempt)", cfg.PlaybackPolicy)
+	}
+	events("priorities", mapKeys(cfg.Priorities))
+
+	if q := cfg.Quiet; q != nil {
+		for i, w := range q.Schedules {
+			if _, err := w.weekdays(); err != nil {
+				add("quiet.schedules[%d]: %v", i, err)
+			}
+			if _, _, err := w.minutes(); err != nil {
+				add("quiet.schedules[%d]: %v", i, err)
+			}
+		}
+		events("quiet.volumes", mapKeys(q.Volumes))
+		for e, v := range q.Volumes {
+			volume("quiet.volumes."+e, v)
+		}
+	}
+
+	if b := cfg.Busy; b != nil {
+		for key, action := range map[string]string{"busy.mic": b.Mic, "busy.playing": b.Playing} {
+			switch action {
+			case busyIgnore, busySkip, busyDuck, busyVisual:
+			default:
+				add("%s: unknown action %q (use skip, duck, or visual)", key, action)
+			}
+		}
+		volume("busy.duck_volume", b.DuckVolume)
+	}
+
+	events("notify", mapKeys(cfg.Notify))
+	for e, n := range cfg.Notify {
+		if n == nil || n.Urgency == "" {
+			continue
+		}
+		if _, ok := notifyUrgencies[n.Urgency]; !ok {
+			add("notify.%s.urgency: unknown urgency %q (use low, normal, or critical)", e, n.Urgency)
+		}
+	}
+
+	if w := cfg.Webhook; w != nil {
+		if w.URL == "" {
+			add("webhook.url: missing")
+		}
+		switch w.Format {
+		case "", "json", "ntfy", "gotify":
+		default:
+			add("webhook.format: unknown format %q (use json, ntfy, or gotify)", w.Format)
+		}
+		events("webhook.events", slices.Clone(w.Events))
+		if w.Timeout < 0 || w.Retries < 0 {
+			add("webhook: timeout and retries must not be negative")
+		}
+	}
+
+	events("speak", mapKeys(cfg.Speak))
+	for e, sc := range cfg.Speak {
+		if sc != nil && sc.Engine != "" && !slices.ContainsFunc(speechEngines, func(se speechEngine) bool { return se.name == sc.Engine }) {
+			add("speak.%s.engine: unknown engine %q", e, sc.Engine)
+		}
+	}
+
+	events("terminal", mapKeys(cfg.Terminal))
+	for e, tc := range cfg.Terminal {
+		if tc == nil {
+			continue
+		}
+		for _, a := range tc.Alerts {
+			switch a {
+			case alertBell, alertOSC9, alertOSC777, alertTmux:
+			default:
+				add("terminal.%s.alerts: unknown alert %q (use bell, osc9, osc777, or tmux)", e, a)
+			}
+		}
+	}
+
+	if r := cfg.Remote; r != nil && (r.URL == "" || r.Secret == "") {
+		add("remote: both url and secret are required")
+	}
+	if s := cfg.Serve; s != nil && s.Secret == "" {
+		add("serve.secret: missing")
+	}
+
+	for name, prof := range cfg.Profiles {
+		if prof == nil {
+			continue
+		}
+		if err := validProfileName(name); err != nil {
+			add("profiles: %v", err)
+		}
+		pc := Config{Profiles: cfg.Profiles}.withProfile(name)
+		pc.Profiles = nil
+		for _, problem := range pc.problems() {
+			add("profiles.%s.%s", name, problem)
+		}
+	}
+	knownProfile := func(name string) bool {
+		return name == defaultProfile || cfg.Profiles[name] != nil
+	}
+	if cfg.Profile != "" && cfg.Profile != autoProfile && !knownProfile(cfg.Profile) {
+		add("profile: no profile named %q", cfg.Profile)
+	}
+	for i, rule := range cfg.ProfileRules {
+		if !knownProfile(rule.Profile) {
+			add("profile_rules[%d]: no profile named %q", i, rule.Profile)
+		}
+		if _, err := rule.matches(time.Now(), func() string { return rule.WiFi }); err != nil {
+			add("profile_rules[%d]: %v", i, err)
+		}
+	}
+
+	sort.Strings(p)
+	return p
+}
+
+func mapKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	return keys
+}
@@ -2,44 +2,108 @@ package main
 
 import (
 	"fmt"
+	"maps"
 	"os"
 	"strconv"
 	"strings"
 )
 
 func cmdVolume() {
-	cfg, err := loadConfig()
+	base, err := loadConfig()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "error loading config: %v\n", err)
 		os.Exit(1)
 	}
+	profile, cfg := activeSettings(base)
+	save := func(cfg Config) {
+		if err := saveConfig(storeProfileSettings(base, profile, cfg)); err != nil {
+			fmt.Fprintf(os.Stderr, "error saving config: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	inProfile := ""
+	if base.Profiles[profile] != nil {
+		inProfile = fmt.Sprintf(" in profile %s", profile)
+	}
+
+	args := os.Args[2:]
+	event := ""
+	if len(args) > 0 && isEventName(args[0]) {
+		event, args = args[0], args[1:]
+	}
 
-	if len(os.Args) == 2 {
-		fmt.Printf("Current volume: %s\n", formatVolume(cfg.Volume))
-		fmt.Println("Set a new level with: claude-bell volume <value>")
+	if len(args) == 0 {
+		if event != "" {
+			fmt.Printf("%s volume: %s%s\n", event, formatVolume(eventVolume(cfg, event)), volumeSource(cfg, event))
+			fmt.Printf("Set it with: claude-bell volume %s <value> (or 'default' to follow the global volume)\n", event)
+			return
+		}
+		fmt.Printf("Current volume%s: %s\n", inProfile, formatVolume(cfg.Volume))
+		for _, e := range EventNames {
+			if _, ok := cfg.Volumes[e]; ok {
+				fmt.Printf("  %-14s %s\n", e+":", formatVolume(eventVolume(cfg, e)))
+			}
+		}
+		fmt.Println("Set a new level with: claude-bell volume [event] <value>")
 		fmt.Println("Accepted formats: 0-1, 0-100, or percent (e.g. 0.65, 65, 65%).")
 		return
 	}
 
-	if len(os.Args) != 3 {
-		fmt.Fprintln(os.Stderr, "usage: claude-bell volume [value]")
+	if len(args) != 1 {
+		fmt.Fprintln(os.Stderr, "usage: claude-bell volume [event] [value]")
 		os.Exit(1)
 	}
 
-	vol, err := parseVolumeArg(os.Args[2])
+	if event != "" && (args[0] == "default" || args[0] == "reset") {
+		volumes := maps.Clone(cfg.Volumes)
+		delete(volumes, event)
+		cfg.Volumes = volumes
+		save(cfg)
+		fmt.Printf("%s volume%s now follows the global volume (%s)\n", event, inProfile, formatVolume(cfg.Volume))
+		return
+	}
+
+	vol, err := parseVolumeArg(args[0])
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "error: %v\n", err)
-		fmt.Fprintln(os.Stderr, "usage: claude-bell volume [value]")
+		fmt.Fprintln(os.Stderr, "usage: claude-bell volume [event] [value]")
 		os.Exit(1)
 	}
 
-	cfg.Volume = vol
-	if err := saveConfig(cfg); err != nil {
-		fmt.Fprintf(os.Stderr, "error saving config: %v\n", err)
-		os.Exit(1)
+	if event == "" {
+		cfg.Volume = vol
+	} else {
+		volumes := maps.Clone(cfg.Volumes)
+		if volumes == nil {
+			volumes = make(map[string]float64)
+		}
+		volumes[event] = vol
+		cfg.Volumes = volumes
+	}
+	save(cfg)
+
+	if event == "" {
+		fmt.Printf("Volume%s set to %s\n", inProfile, formatVolume(vol))
+	} else {
+		fmt.Printf("%s volume%s set to %s\n", event, inProfile, formatVolume(vol))
+	}
+}
+
+// volumeSource notes where an event's effective volume comes from.
+func volumeSource(cfg Config, event string) string {
+	if _, ok := cfg.Volumes[event]; ok {
+		return ""
 	}
+	return " (global)"
+}
 
-	fmt.Printf("Volume set to %s\n", formatVolume(vol))
+func isEventName(s string) bool {
+	for _, e := range EventNames {
+		if e == s {
+			return true
+		}
+	}
+	return false
 }
 
 func parseVolumeArg(input string) (float64, error) {
@@ -24,12 +24,29 @@ func generateWAV(path string, tones []Tone) error {
 	return writeWAV(path, samples)
 }
 
-// renderTones generates PCM samples for a sequence of tones.
+// Loudness normalization. Every sound is scaled so its A-weighted RMS over
+// the sounding (non-gap) samples matches a 1 kHz tone at loudnessRef
+// amplitude. Low tones get boosted and high tones cut, so switching presets
+// keeps the bell at roughly the same perceived loudness. loudnessRef is low
+// enough that every built-in preset, down to Low Buzz at 220 Hz, reaches it
+// without exceeding peakCeiling; only custom sounds lower than that are held
+// back by the ceiling and play quieter.
+const (
+	loudnessRef = 0.28
+	peakCeiling = 0.95
+)
+
+// renderTones generates PCM samples for a sequence of tones, normalized to a
+// common perceived loudness.
 func renderTones(tones []Tone) []int16 {
-	var samples []int16
+	var raw []float64
+	var weighted float64 // sum of squared A-weighted samples
+	sounding := 0
+	peak := 0.0
 	for _, t := range tones {
 		numSamples := int(t.Duration * sampleRate)
 		fade := calcFadeSamples()
+		weight := aWeighting(t.Freq)
 		for i := 0; i < numSamples; i++ {
 			var sample float64
 			if t.Freq > 0 {
@@ -42,14 +59,43 @@ func renderTones(tones []Tone) []int16 {
 				if i >= numSamples-fade {
 					sample *= float64(numSamples-1-i) / float64(fade)
 				}
-				sample *= 0.5 // master volume
+				weighted += (sample * weight) * (sample * weight)
+				sounding++
+				peak = math.Max(peak, math.Abs(sample))
 			}
-			samples = append(samples, int16(sample*math.MaxInt16))
+			raw = append(raw, sample)
 		}
 	}
+
+	gain := 0.0
+	if sounding > 0 && weighted > 0 {
+		target := loudnessRef / math.Sqrt2 // RMS of the reference tone
+		gain = target / math.Sqrt(weighted/float64(sounding))
+		// Never let the loudest sample clip.
+		gain = math.Min(gain, peakCeiling/peak)
+	}
+
+	samples := make([]int16, len(raw))
+	for i, s := range raw {
+		samples[i] = int16(s * gain * math.MaxInt16)
+	}
 	return samples
 }
 
+// aWeighting returns the IEC 61672 A-weighting gain for a frequency as a
+// linear factor, normalized to 1 at 1 kHz.
+func aWeighting(freq float64) float64 {
+	if freq <= 0 {
+		return 0
+	}
+	ra := func(f float64) float64 {
+		f2 := f * f
+		return 12194 * 12194 * f2 * f2 /
+			((f2 + 20.6*20.6) * math.Sqrt((f2+107.7*107.7)*(f2+737.9*737.9)) * (f2 + 12194*12194))
+	}
+	return ra(freq) / ra(1000)
+}
+
 // writeWAV writes PCM samples as a 16-bit mono WAV file.
 func writeWAV(path string, samples []int16) error {
 	f, err := os.Create(path)
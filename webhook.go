@@ -0,0 +1,160 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// WebhookConfig posts events to an HTTP endpoint. Header values are
+// templates over eventContext, so secrets can come from the environment:
+// "Authorization": "Bearer {{env \"NTFY_TOKEN\"}}".
+type WebhookConfig struct {
+	URL     string            `json:"url"`
+	Format  string            `json:"format,omitempty"` // json (default), ntfy, or gotify
+	Events  []string          `json:"events,omitempty"` // empty means every event
+	Headers map[string]string `json:"headers,omitempty"`
+	Timeout float64           `json:"timeout,omitempty"` // seconds per attempt
+	Retries int               `json:"retries,omitempty"`
+}
+
+// Push priorities per event: ntfy uses 1-5 and Gotify 0-10.
+var (
+	ntfyPriorities   = map[string]int{"stop": 3, "notification": 4, "limit": 5}
+	gotifyPriorities = map[string]int{"stop": 4, "notification": 6, "limit": 8}
+)
+
+const (
+	defaultWebhookTimeout = 5 * time.Second
+	webhookRetryDelay     = 500 * time.Millisecond
+)
+
+// webhookBody is the default JSON format.
+type webhookBody struct {
+	Event       string      `json:"event"`
+	Description string      `json:"description"`
+	Message     string      `json:"message"`
+	Preset      string      `json:"preset,omitempty"`
+	Project     string      `json:"project,omitempty"`
+	Dir         string      `json:"dir,omitempty"`
+	Host        string      `json:"host,omitempty"`
+	Time        time.Time   `json:"time"`
+	Payload     hookPayload `json:"payload"`
+}
+
+// wantsEvent reports whether the webhook is configured for event.
+func (w *WebhookConfig) wantsEvent(event string) bool {
+	return w != nil && w.URL != "" && (len(w.Events) == 0 || slices.Contains(w.Events, event))
+}
+
+// sendWebhook posts an event, retrying on network errors, 429 and 5xx.
+func sendWebhook(w *WebhookConfig, ctx eventContext, payload hookPayload) error {
+	body, contentType, extra, err := w.encode(ctx, payload)
+	if err != nil {
+		return err
+	}
+
+	timeout := defaultWebhookTimeout
+	if w.Timeout > 0 {
+		timeout = seconds(w.Timeout)
+	}
+	client := &http.Client{Timeout: timeout}
+
+	for attempt := 0; ; attempt++ {
+		err = postWebhook(client, w, ctx, body, contentType, extra)
+		if err == nil || attempt >= w.Retries {
+			return err
+		}
+		if _, permanent := err.(webhookStatusError); permanent {
+			return err
+		}
+		time.Sleep(webhookRetryDelay * time.Duration(attempt+1))
+	}
+}
+
+// webhookStatusError is a response status that retrying will not fix.
+type webhookStatusError struct {
+	status string
+}
+
+func (e webhookStatusError) Error() string {
+	return "webhook returned " + e.status
+}
+
+func postWebhook(client *http.Client, w *WebhookConfig, ctx eventContext, body []byte, contentType string, extra map[string]string) error {
+	req, err := http.NewRequest(http.MethodPost, w.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("User-Agent", "claude-bell")
+	for k, v := range extra {
+		req.Header.Set(k, v)
+	}
+	for k, v := range w.Headers {
+		req.Header.Set(k, ctx.expand(v))
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	resp.Body.Close()
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return nil
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return fmt.Errorf("webhook returned %s", resp.Status)
+	default:
+		return webhookStatusError{resp.Status}
+	}
+}
+
+// encode builds the request body, its content type, and any headers the
+// format needs.
+func (w *WebhookConfig) encode(ctx eventContext, payload hookPayload) ([]byte, string, map[string]string, error) {
+	title := "Claude Code"
+	if ctx.Project != "" {
+		title += " - " + ctx.Project
+	}
+
+	switch w.Format {
+	case "", "json":
+		data, err := json.Marshal(webhookBody{
+			Event:       ctx.Event,
+			Description: ctx.Description,
+			Message:     ctx.Message,
+			Preset:      ctx.Preset,
+			Project:     ctx.Project,
+			Dir:         ctx.Dir,
+			Host:        ctx.Host,
+			Time:        time.Now().UTC(),
+			Payload:     payload,
+		})
+		return data, "application/json", nil, err
+	case "ntfy":
+		// https://docs.ntfy.sh/publish/
+		headers := map[string]string{
+			"Title":    title,
+			"Tags":     "bell," + ctx.Event,
+			"Priority": strconv.Itoa(ntfyPriorities[ctx.Event]),
+		}
+		return []byte(ctx.Message + " (" + ctx.Host + ")"), "text/plain; charset=utf-8", headers, nil
+	case "gotify":
+		// https://gotify.net/api-docs#/message/createMessage
+		data, err := json.Marshal(map[string]any{
+			"title":    title,
+			"message":  ctx.Message + " (" + ctx.Host + ")",
+			"priority": gotifyPriorities[ctx.Event],
+		})
+		return data, "application/json", nil, err
+	}
+	return nil, "", nil, fmt.Errorf("unknown webhook format %q (use json, ntfy, or gotify)", w.Format)
+}
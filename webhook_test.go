@@ -0,0 +1,158 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+var testEventContext = eventContext{
+	Event:       "stop",
+	Description: EventDescriptions["stop"],
+	Message:     "Finished the refactor",
+	Project:     "claude-bell",
+	Host:        "laptop",
+}
+
+// statusServer answers with the given statuses in turn, repeating the last
+// one, and counts the requests it gets.
+func statusServer(t *testing.T, statuses ...int) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var count atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(count.Add(1))
+		w.WriteHeader(statuses[min(n, len(statuses))-1])
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &count
+}
+
+func TestWebhookRetriesServerErrors(t *testing.T) {
+	srv, count := statusServer(t, http.StatusServiceUnavailable, http.StatusOK)
+	w := &WebhookConfig{URL: srv.URL, Retries: 2}
+	if err := sendWebhook(w, testEventContext, hookPayload{}); err != nil {
+		t.Fatalf("sendWebhook: %v", err)
+	}
+	if got := count.Load(); got != 2 {
+		t.Errorf("got %d requests, want 2", got)
+	}
+}
+
+func TestWebhookGivesUpAfterRetries(t *testing.T) {
+	srv, count := statusServer(t, http.StatusInternalServerError)
+	w := &WebhookConfig{URL: srv.URL, Retries: 1}
+	if err := sendWebhook(w, testEventContext, hookPayload{}); err == nil {
+		t.Fatal("sendWebhook succeeded against a failing server")
+	}
+	if got := count.Load(); got != 2 {
+		t.Errorf("got %d requests, want 2", got)
+	}
+}
+
+func TestWebhookDoesNotRetryClientErrors(t *testing.T) {
+	srv, count := statusServer(t, http.StatusUnauthorized)
+	w := &WebhookConfig{URL: srv.URL, Retries: 3}
+	err := sendWebhook(w, testEventContext, hookPayload{})
+	if _, ok := err.(webhookStatusError); !ok {
+		t.Fatalf("got error %v, want a webhookStatusError", err)
+	}
+	if got := count.Load(); got != 1 {
+		t.Errorf("got %d requests, want 1", got)
+	}
+}
+
+// capturedRequest is what captureServer saw.
+type capturedRequest struct {
+	header http.Header
+	body   []byte
+}
+
+// captureServer records the one request it expects.
+func captureServer(t *testing.T) (*httptest.Server, <-chan capturedRequest) {
+	t.Helper()
+	got := make(chan capturedRequest, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		got <- capturedRequest{r.Header.Clone(), body}
+	}))
+	t.Cleanup(srv.Close)
+	return srv, got
+}
+
+func TestWebhookJSON(t *testing.T) {
+	srv, got := captureServer(t)
+	t.Setenv("CLAUDE_BELL_TEST_TOKEN", "s3cret")
+	w := &WebhookConfig{URL: srv.URL, Headers: map[string]string{"Authorization": `Bearer {{env "CLAUDE_BELL_TEST_TOKEN"}}`}}
+	payload := hookPayload{SessionID: "abc", HookEventName: "Stop"}
+	if err := sendWebhook(w, testEventContext, payload); err != nil {
+		t.Fatalf("sendWebhook: %v", err)
+	}
+	req := <-got
+
+	if ct := req.header.Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	if auth := req.header.Get("Authorization"); auth != "Bearer s3cret" {
+		t.Errorf("Authorization = %q", auth)
+	}
+	var body webhookBody
+	if err := json.Unmarshal(req.body, &body); err != nil {
+		t.Fatalf("body %s: %v", req.body, err)
+	}
+	if body.Event != "stop" || body.Message != "Finished the refactor" || body.Project != "claude-bell" || body.Payload != payload {
+		t.Errorf("unexpected body %s", req.body)
+	}
+}
+
+func TestWebhookNtfy(t *testing.T) {
+	srv, got := captureServer(t)
+	w := &WebhookConfig{URL: srv.URL, Format: "ntfy"}
+	if err := sendWebhook(w, testEventContext, hookPayload{}); err != nil {
+		t.Fatalf("sendWebhook: %v", err)
+	}
+	req := <-got
+
+	if body := string(req.body); body != "Finished the refactor (laptop)" {
+		t.Errorf("body = %q", body)
+	}
+	want := map[string]string{
+		"Content-Type": "text/plain; charset=utf-8",
+		"Title":        "Claude Code - claude-bell",
+		"Tags":         "bell,stop",
+		"Priority":     "3",
+	}
+	for k, v := range want {
+		if got := req.header.Get(k); got != v {
+			t.Errorf("%s = %q, want %q", k, got, v)
+		}
+	}
+}
+
+func TestWebhookGotify(t *testing.T) {
+	srv, got := captureServer(t)
+	w := &WebhookConfig{URL: srv.URL, Format: "gotify"}
+	ctx := testEventContext
+	ctx.Event = "limit"
+	if err := sendWebhook(w, ctx, hookPayload{}); err != nil {
+		t.Fatalf("sendWebhook: %v", err)
+	}
+	req := <-got
+
+	if ct := req.header.Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	var body struct {
+		Title    string `json:"title"`
+		Message  string `json:"message"`
+		Priority int    `json:"priority"`
+	}
+	if err := json.Unmarshal(req.body, &body); err != nil {
+		t.Fatalf("body %s: %v", req.body, err)
+	}
+	if body.Title != "Claude Code - claude-bell" || body.Message != "Finished the refactor (laptop)" || body.Priority != 8 {
+		t.Errorf("unexpected body %s", req.body)
+	}
+}